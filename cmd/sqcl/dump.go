@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mastar3104/sqcl/internal/dump"
+)
+
+func runDump(args []string) {
+	fs, resolve := dump.FlagSet("dump", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "Database connection string")
+	driver := fs.String("driver", "mysql", "Database driver")
+	connectionName := fs.String("c", "", "Use saved connection by name")
+	output := fs.String("o", "", "Output file (default: stdout)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sqcl dump (-c <name> | -dsn 'connection_string') [-tables a,b] [-no-data] [-where ...] [-o file]\n\n")
+		fmt.Fprintf(os.Stderr, "Writes the schema and data of the current database as SQL.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" && *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	session := openSession(*connectionName, *dsn, *driver)
+	defer session.Close()
+
+	if err := dump.Run(context.Background(), session.Connector, session.Metadata, session.Dialect, w, resolve()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		session.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/batch"
+)
+
+func runExecBatch(args []string) {
+	fs, resolve := batch.FlagSet("exec-batch", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "Database connection string")
+	driver := fs.String("driver", "mysql", "Database driver")
+	connectionName := fs.String("c", "", "Use saved connection by name")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sqcl exec-batch (-c <name> | -dsn 'connection_string') [options] <file> <statement>\n\n")
+		fmt.Fprintf(os.Stderr, "Runs the statement once per CSV/TSV row, binding fields to ? placeholders.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	file := fs.Arg(0)
+	statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(fs.Args()[1:], " ")), ";")
+
+	opts, err := resolve(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Progress = os.Stderr
+
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	session := openSession(*connectionName, *dsn, *driver)
+	defer session.Close()
+
+	result, err := batch.Run(context.Background(), session.Connector, f, statement, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		session.Close()
+		os.Exit(1)
+	}
+	fmt.Println(result.Summary())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mastar3104/sqcl/internal/export"
+)
+
+func runExport(args []string) {
+	fs, resolve := export.FlagSet("export", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "Database connection string")
+	driver := fs.String("driver", "mysql", "Database driver")
+	connectionName := fs.String("c", "", "Use saved connection by name")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sqcl export (-c <name> | -dsn 'connection_string') (-query 'SELECT ...' | -table <table>) [-format csv|json] [-o file]\n\n")
+		fmt.Fprintf(os.Stderr, "Streams a query or table to a file.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts, err := resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Progress = os.Stderr
+
+	session := openSession(*connectionName, *dsn, *driver)
+	defer session.Close()
+
+	result, err := export.Run(context.Background(), session.Connector, session.Metadata, session.Dialect, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		session.Close()
+		os.Exit(1)
+	}
+
+	destination := opts.Output
+	if destination == "" || destination == "-" {
+		destination = "stdout"
+	}
+	fmt.Fprintf(os.Stderr, "%d row(s) exported to %s in %d chunk(s)\nTime: %v\n", result.Rows, destination, result.Chunks, result.Duration)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mastar3104/sqcl/internal/completion"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+	"github.com/mastar3104/sqcl/internal/pretty"
+)
+
+func runFmt(args []string) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	keywordCase := fs.String("case", "upper", "Keyword case (upper, lower, preserve)")
+	write := fs.Bool("w", false, "Write the result back to the files")
+	list := fs.Bool("l", false, "List files whose formatting differs and exit with status 1")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sqcl fmt [-case upper|lower|preserve] [-w | -l] [file.sql ...]\n\n")
+		fmt.Fprintf(os.Stderr, "Formats SQL files, or standard input when no file is given.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	kc, err := completion.ParseKeywordCase(*keywordCase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := pretty.Options{Dialect: mysql.NewDialect(), KeywordCase: kc}
+
+	if fs.NArg() == 0 {
+		if *write || *list {
+			fmt.Fprintln(os.Stderr, "Error: -w and -l require files")
+			os.Exit(1)
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(pretty.Format(string(data), opts))
+		return
+	}
+
+	unformatted := false
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		formatted := pretty.Format(string(data), opts) + "\n"
+
+		switch {
+		case *list:
+			if formatted != string(data) {
+				fmt.Println(path)
+				unformatted = true
+			}
+		case *write:
+			if formatted == string(data) {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err == nil {
+				err = os.WriteFile(path, []byte(formatted), info.Mode().Perm())
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Print(formatted)
+		}
+	}
+
+	if unformatted {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mastar3104/sqcl/internal/importer"
+)
+
+func runImport(args []string) {
+	fs, resolve := importer.FlagSet("import", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "Database connection string")
+	driver := fs.String("driver", "mysql", "Database driver")
+	connectionName := fs.String("c", "", "Use saved connection by name")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sqcl import (-c <name> | -dsn 'connection_string') -table <table> [options] <file>\n\n")
+		fmt.Fprintf(os.Stderr, "Imports a CSV, TSV or JSON file into a table.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	file := fs.Arg(0)
+	opts, err := resolve(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Progress = os.Stderr
+
+	session := openSession(*connectionName, *dsn, *driver)
+	defer session.Close()
+
+	result, err := importer.Run(context.Background(), session.Connector, session.Metadata, session.Dialect, file, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		session.Close()
+		os.Exit(1)
+	}
+	fmt.Println(result.Summary(opts.DryRun))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -28,6 +29,21 @@ func main() {
 		case "remove":
 			runRemove(os.Args[2:])
 			return
+		case "exec-batch":
+			runExecBatch(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "dump":
+			runDump(os.Args[2:])
+			return
+		case "fmt":
+			runFmt(os.Args[2:])
+			return
 		}
 	}
 
@@ -133,7 +149,12 @@ func runConnect() {
 		fmt.Fprintf(os.Stderr, "  sqcl -c <connection_name>\n")
 		fmt.Fprintf(os.Stderr, "  sqcl save <name> -dsn 'connection_string'\n")
 		fmt.Fprintf(os.Stderr, "  sqcl list\n")
-		fmt.Fprintf(os.Stderr, "  sqcl remove <name>\n\n")
+		fmt.Fprintf(os.Stderr, "  sqcl remove <name>\n")
+		fmt.Fprintf(os.Stderr, "  sqcl exec-batch -c <connection_name> <file> <statement>\n")
+		fmt.Fprintf(os.Stderr, "  sqcl import -c <connection_name> -table <table> <file>\n")
+		fmt.Fprintf(os.Stderr, "  sqcl export -c <connection_name> (-query 'SELECT ...' | -table <table>) -o <file>\n")
+		fmt.Fprintf(os.Stderr, "  sqcl dump -c <connection_name> [-tables a,b] [-no-data]\n")
+		fmt.Fprintf(os.Stderr, "  sqcl fmt [-case upper|lower|preserve] [-w | -l] [file.sql ...]\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
@@ -146,24 +167,7 @@ func runConnect() {
 	}
 
 	// Resolve connection from saved name if specified
-	actualDSN := *dsn
-	actualDriver := *driver
-
-	if *connectionName != "" {
-		manager := connections.NewManager()
-		conn, err := manager.Get(*connectionName)
-		if err != nil {
-			if err == connections.ErrConnectionNotFound {
-				fmt.Fprintf(os.Stderr, "Error: connection '%s' not found\n", *connectionName)
-				fmt.Fprintln(os.Stderr, "Use 'sqcl list' to see saved connections.")
-			} else {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			}
-			os.Exit(1)
-		}
-		actualDSN = conn.DSN
-		actualDriver = conn.Driver
-	}
+	actualDSN, actualDriver := resolveConnection(*connectionName, *dsn, *driver)
 
 	if actualDSN == "" {
 		fmt.Fprintln(os.Stderr, "Error: -dsn flag or -c flag is required")
@@ -174,10 +178,11 @@ func runConnect() {
 
 	// Create and run application
 	config := app.Config{
-		DSN:         actualDSN,
-		Driver:      actualDriver,
-		HistoryFile: *historyFile,
-		CacheTTL:    *cacheTTL,
+		DSN:            actualDSN,
+		Driver:         actualDriver,
+		ConnectionName: *connectionName,
+		HistoryFile:    *historyFile,
+		CacheTTL:       *cacheTTL,
 	}
 
 	application, err := app.New(config)
@@ -191,3 +196,43 @@ func runConnect() {
 		os.Exit(1)
 	}
 }
+
+// resolveConnection returns the DSN and driver to use, looking up the saved
+// connection when a name is given. Exits on lookup errors.
+func resolveConnection(connectionName, dsn, driver string) (string, string) {
+	if connectionName == "" {
+		return dsn, driver
+	}
+
+	manager := connections.NewManager()
+	conn, err := manager.Get(connectionName)
+	if err != nil {
+		if err == connections.ErrConnectionNotFound {
+			fmt.Fprintf(os.Stderr, "Error: connection '%s' not found\n", connectionName)
+			fmt.Fprintln(os.Stderr, "Use 'sqcl list' to see saved connections.")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	return conn.DSN, conn.Driver
+}
+
+// openSession resolves the connection and connects to the database. Exits on failure.
+func openSession(connectionName, dsn, driver string) *app.Session {
+	actualDSN, actualDriver := resolveConnection(connectionName, dsn, driver)
+	if actualDSN == "" {
+		fmt.Fprintln(os.Stderr, "Error: -dsn flag or -c flag is required")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	session, err := app.Open(ctx, actualDriver, actualDSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return session
+}
@@ -10,8 +10,8 @@ import (
 	"time"
 
 	"github.com/mastar3104/sqcl/internal/cache"
+	"github.com/mastar3104/sqcl/internal/connections"
 	"github.com/mastar3104/sqcl/internal/db"
-	"github.com/mastar3104/sqcl/internal/db/mysql"
 	"github.com/mastar3104/sqcl/internal/history"
 	"github.com/mastar3104/sqcl/internal/repl"
 )
@@ -64,65 +64,118 @@ func (a *App) Run() error {
 }
 
 func (a *App) initialize(ctx context.Context) error {
-	// Create connector based on driver
-	var connector db.Connector
-	var dialect db.Dialect
-
-	switch a.config.Driver {
-	case "mysql":
-		connector = mysql.NewConnector()
-		dialect = mysql.NewDialect()
-	default:
-		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, a.config.Driver)
-	}
-
 	// Connect to database
 	connectCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	if err := connector.Connect(connectCtx, a.config.DSN); err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
-	}
-	a.connector = connector
-
-	// Create metadata provider
-	switch a.config.Driver {
-	case "mysql":
-		a.metadata = mysql.NewMetadataProvider(connector.DB())
+	session, err := Open(connectCtx, a.config.Driver, a.config.DSN)
+	if err != nil {
+		return err
 	}
-	a.dialect = dialect
+	a.connector = session.Connector
+	a.metadata = session.Metadata
+	a.dialect = session.Dialect
 
 	// Create metadata cache
-	a.cache = cache.NewMetadataCache(a.metadata, a.config.CacheTTL)
+	a.cache = a.newCache(connectCtx, session, a.config.ConnectionName)
 
 	// Ensure history directory exists
 	if err := history.EnsureHistoryDir(a.config.HistoryFile); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not create history directory: %v\n", err)
 	}
 
+	settings, err := LoadSettings(SettingsFilePath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read config file: %v\n", err)
+	}
+
 	// Create REPL
 	r, err := repl.New(repl.Config{
 		Connector:   a.connector,
 		Cache:       a.cache,
 		Dialect:     a.dialect,
 		HistoryFile: a.config.HistoryFile,
+		Connect:     a.connect,
+		Theme:       settings.Theme,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create REPL: %w", err)
 	}
 	a.repl = r
 
-	// Preload metadata cache in background
+	return nil
+}
+
+// newCache creates the metadata cache of a session. Saved connections
+// start from the persisted cache, which is then revalidated against the
+// schema in background; others preload the table list.
+func (a *App) newCache(ctx context.Context, session *Session, connectionName string) *cache.MetadataCache {
+	c := cache.NewMetadataCache(session.Metadata, a.config.CacheTTL)
+	if database, err := session.Connector.GetCurrentDatabase(ctx); err == nil {
+		c.SetCurrentDatabase(database)
+	}
+
+	if connectionName == "" {
+		go func() {
+			preloadCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, _ = c.GetTables(preloadCtx, "")
+		}()
+		return c
+	}
+
+	if err := c.Persist(cache.GetCacheFilePath(connectionName)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load metadata cache: %v\n", err)
+	}
 	go func() {
-		preloadCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		revalidateCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		_, _ = a.cache.GetTables(preloadCtx)
+		if err := c.Revalidate(revalidateCtx); err == nil {
+			_ = c.Save()
+		}
 	}()
+	return c
+}
 
-	return nil
+// connect opens a saved connection for the REPL's :connect command and
+// closes the current one once the new one is up.
+func (a *App) connect(name string) (*repl.Session, error) {
+	conn, err := connections.NewManager().Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	session, err := Open(ctx, conn.Driver, conn.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save metadata cache: %v\n", err)
+	}
+	a.connector.Close()
+
+	a.connector = session.Connector
+	a.metadata = session.Metadata
+	a.dialect = session.Dialect
+	a.cache = a.newCache(ctx, session, name)
+	a.config.ConnectionName = name
+
+	return &repl.Session{
+		Connector: a.connector,
+		Cache:     a.cache,
+		Dialect:   a.dialect,
+	}, nil
 }
 
 func (a *App) cleanup() {
+	if a.cache != nil {
+		if err := a.cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save metadata cache: %v\n", err)
+		}
+	}
 	if a.repl != nil {
 		a.repl.Close()
 	}
@@ -10,6 +10,9 @@ import (
 type Config struct {
 	DSN          string
 	Driver       string
+	// ConnectionName is the saved connection in use, if any. It names the
+	// persistent metadata cache file.
+	ConnectionName string
 	HistoryFile  string
 	CacheTTL     time.Duration
 }
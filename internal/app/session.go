@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+// Session bundles the database components used outside of the REPL,
+// e.g. by non-interactive subcommands.
+type Session struct {
+	Connector db.Connector
+	Metadata  db.MetadataProvider
+	Dialect   db.Dialect
+}
+
+// Open connects to the database and creates the driver-specific components.
+func Open(ctx context.Context, driver, dsn string) (*Session, error) {
+	if dsn == "" {
+		return nil, ErrDSNRequired
+	}
+
+	var connector db.Connector
+	var dialect db.Dialect
+
+	switch driver {
+	case "mysql":
+		connector = mysql.NewConnector()
+		dialect = mysql.NewDialect()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
+	}
+
+	if err := connector.Connect(ctx, dsn); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var metadata db.MetadataProvider
+	switch driver {
+	case "mysql":
+		metadata = mysql.NewMetadataProvider(connector.DB())
+	}
+
+	return &Session{
+		Connector: connector,
+		Metadata:  metadata,
+		Dialect:   dialect,
+	}, nil
+}
+
+// Close closes the underlying database connection.
+func (s *Session) Close() error {
+	return s.Connector.Close()
+}
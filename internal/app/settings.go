@@ -0,0 +1,37 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Settings holds user preferences read from the config file.
+type Settings struct {
+	// Theme names the color theme, e.g. "light".
+	Theme string `json:"theme"`
+}
+
+// SettingsFilePath returns the path to the config file.
+func SettingsFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".sqcl/config.json"
+	}
+	return filepath.Join(home, ".sqcl", "config.json")
+}
+
+// LoadSettings reads the config file at path. A missing file yields the
+// zero Settings.
+func LoadSettings(path string) (Settings, error) {
+	var settings Settings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	err = json.Unmarshal(data, &settings)
+	return settings, err
+}
@@ -0,0 +1,294 @@
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/placeholder"
+)
+
+var (
+	ErrNoPlaceholders = errors.New("statement has no ? placeholders")
+	ErrColumnNotFound = errors.New("column not found in header")
+)
+
+// ErrorMode controls what happens when a row fails to execute.
+type ErrorMode int
+
+const (
+	// ErrorStop rolls back the current batch and stops.
+	ErrorStop ErrorMode = iota
+	// ErrorSkip records the failure and continues with the next row. When
+	// the server rolls back the transaction, as on a deadlock, the rows of
+	// the current batch are recorded as failed as well.
+	ErrorSkip
+)
+
+// ParseErrorMode converts "stop" or "skip" to an ErrorMode.
+func ParseErrorMode(s string) (ErrorMode, error) {
+	switch strings.ToLower(s) {
+	case "stop", "":
+		return ErrorStop, nil
+	case "skip":
+		return ErrorSkip, nil
+	default:
+		return ErrorStop, fmt.Errorf("unknown error mode: %s (available: stop, skip)", s)
+	}
+}
+
+// Options holds configuration for a batch run.
+type Options struct {
+	// Delimiter is the field separator (',' for CSV, '\t' for TSV).
+	Delimiter rune
+	// Header indicates that the first row contains column names.
+	Header bool
+	// Columns maps header names to placeholders in order.
+	// When empty, columns are mapped to placeholders by position.
+	Columns []string
+	// BatchSize is the number of rows per transaction. 0 commits once at the end.
+	BatchSize int
+	// OnError selects stop or skip behavior on row errors.
+	OnError ErrorMode
+	// Progress receives progress messages. May be nil.
+	Progress io.Writer
+}
+
+// DefaultOptions returns the default batch options.
+func DefaultOptions() Options {
+	return Options{
+		Delimiter: ',',
+		Header:    true,
+		BatchSize: 1000,
+		OnError:   ErrorStop,
+	}
+}
+
+// DelimiterForFile returns the field delimiter implied by the file extension.
+func DelimiterForFile(path string) rune {
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// RowError describes a failed row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Result summarizes a batch run.
+type Result struct {
+	Executed     int
+	Committed    int
+	RowsAffected int64
+	Failed       []RowError
+	Duration     time.Duration
+}
+
+// Summary returns a human-readable summary of the result.
+func (r *Result) Summary() string {
+	output := fmt.Sprintf("Batch complete: %d row(s) executed, %d committed, %d row(s) affected",
+		r.Executed, r.Committed, r.RowsAffected)
+	if len(r.Failed) > 0 {
+		output += fmt.Sprintf(", %d skipped", len(r.Failed))
+		for _, f := range r.Failed {
+			output += fmt.Sprintf("\n  line %d: %v", f.Line, f.Err)
+		}
+	}
+	output += fmt.Sprintf("\nTime: %v", r.Duration)
+	return output
+}
+
+// Run executes statement once per row read from r inside transactions
+// of opts.BatchSize rows.
+func Run(ctx context.Context, conn db.Connector, r io.Reader, statement string, opts Options) (*Result, error) {
+	start := time.Now()
+	result := &Result{}
+	defer func() { result.Duration = time.Since(start) }()
+
+	count := placeholder.CountPlaceholders(statement)
+	if count == 0 {
+		return result, ErrNoPlaceholders
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	reader.FieldsPerRecord = -1
+
+	var mapping []int
+	if opts.Header {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read header: %w", err)
+		}
+		mapping, err = mapColumns(header, opts.Columns, count)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		if len(opts.Columns) > 0 {
+			return result, fmt.Errorf("column names require a header row")
+		}
+		mapping = positional(count)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// pending holds the lines of the rows executed in tx
+	var pending []int
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			tx.Rollback()
+			return result, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		args, err := buildArgs(record, mapping)
+		if err == nil {
+			var res *db.QueryResult
+			res, err = tx.ExecuteWithParams(ctx, statement, args)
+			if err == nil {
+				result.RowsAffected += res.RowsAffected
+			}
+		}
+		result.Executed++
+
+		if err != nil {
+			if opts.OnError == ErrorStop {
+				tx.Rollback()
+				return result, fmt.Errorf("line %d: %w (current batch rolled back, %d row(s) committed)", line, err, result.Committed)
+			}
+			if errors.Is(err, db.ErrTransactionAborted) {
+				// The rows executed so far in tx were rolled back with it
+				tx.Rollback()
+				for _, l := range pending {
+					result.Failed = append(result.Failed, RowError{Line: l, Err: err})
+				}
+				pending = nil
+				var beginErr error
+				if tx, beginErr = conn.Begin(ctx); beginErr != nil {
+					return result, fmt.Errorf("failed to begin transaction: %w", beginErr)
+				}
+			}
+			result.Failed = append(result.Failed, RowError{Line: line, Err: err})
+			continue
+		}
+		pending = append(pending, line)
+
+		if opts.BatchSize > 0 && len(pending) >= opts.BatchSize {
+			if err := tx.Commit(); err != nil {
+				return result, fmt.Errorf("commit failed: %w", err)
+			}
+			result.Committed += len(pending)
+			pending = nil
+			if opts.Progress != nil {
+				fmt.Fprintf(opts.Progress, "Committed %d row(s) (%v)\n", result.Committed, time.Since(start).Round(time.Millisecond))
+			}
+
+			tx, err = conn.Begin(ctx)
+			if err != nil {
+				return result, fmt.Errorf("failed to begin transaction: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit failed: %w", err)
+	}
+	result.Committed += len(pending)
+	return result, nil
+}
+
+// mapColumns resolves which record field feeds each placeholder.
+func mapColumns(header, columns []string, count int) ([]int, error) {
+	if len(columns) == 0 {
+		return positional(count), nil
+	}
+	if len(columns) != count {
+		return nil, fmt.Errorf("%d column(s) given for %d placeholder(s)", len(columns), count)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	mapping := make([]int, count)
+	for i, name := range columns {
+		pos, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, name)
+		}
+		mapping[i] = pos
+	}
+	return mapping, nil
+}
+
+func positional(count int) []int {
+	mapping := make([]int, count)
+	for i := range mapping {
+		mapping[i] = i
+	}
+	return mapping
+}
+
+func buildArgs(record []string, mapping []int) ([]interface{}, error) {
+	args := make([]interface{}, len(mapping))
+	for i, pos := range mapping {
+		if pos >= len(record) {
+			return nil, fmt.Errorf("row has %d field(s), placeholder %d needs field %d", len(record), i+1, pos+1)
+		}
+		args[i] = placeholder.ParseValue(record[pos])
+	}
+	return args, nil
+}
+
+// FlagSet returns a flag set with the batch options shared by the
+// exec-batch subcommand and the :exec-batch REPL command. The returned
+// function resolves the options for the given input file after parsing.
+func FlagSet(name string, errorHandling flag.ErrorHandling) (*flag.FlagSet, func(file string) (Options, error)) {
+	fs := flag.NewFlagSet(name, errorHandling)
+	columns := fs.String("columns", "", "Comma-separated header names mapped to placeholders in order")
+	batchSize := fs.Int("batch-size", 1000, "Rows per transaction (0 = single transaction)")
+	onError := fs.String("on-error", "stop", "Behavior on row errors (stop, skip)")
+	noHeader := fs.Bool("no-header", false, "Treat the first row as data")
+	tsv := fs.Bool("tsv", false, "Read tab-separated values (default: by file extension)")
+
+	return fs, func(file string) (Options, error) {
+		opts := DefaultOptions()
+		mode, err := ParseErrorMode(*onError)
+		if err != nil {
+			return opts, err
+		}
+		opts.OnError = mode
+		opts.BatchSize = *batchSize
+		opts.Header = !*noHeader
+		opts.Delimiter = DelimiterForFile(file)
+		if *tsv {
+			opts.Delimiter = '\t'
+		}
+		if *columns != "" {
+			opts.Columns = strings.Split(*columns, ",")
+		}
+		return opts, nil
+	}
+}
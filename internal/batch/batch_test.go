@@ -0,0 +1,192 @@
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+type fakeTx struct {
+	conn *fakeConnector
+	rows [][]interface{}
+}
+
+func (t *fakeTx) ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*db.QueryResult, error) {
+	if s, ok := args[0].(string); ok && s == "bad" {
+		return nil, errors.New("bad row")
+	}
+	if s, ok := args[0].(string); ok && s == "deadlock" {
+		return nil, fmt.Errorf("%w: deadlock", db.ErrTransactionAborted)
+	}
+	t.rows = append(t.rows, args)
+	return &db.QueryResult{RowsAffected: 1}, nil
+}
+
+func (t *fakeTx) Commit() error {
+	t.conn.committed = append(t.conn.committed, t.rows...)
+	t.conn.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.rollbacks++
+	return nil
+}
+
+type fakeConnector struct {
+	committed [][]interface{}
+	commits   int
+	rollbacks int
+}
+
+func (c *fakeConnector) Connect(ctx context.Context, dsn string) error { return nil }
+func (c *fakeConnector) Close() error                                  { return nil }
+func (c *fakeConnector) Execute(ctx context.Context, query string) (*db.QueryResult, error) {
+	return nil, nil
+}
+func (c *fakeConnector) ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*db.QueryResult, error) {
+	return nil, nil
+}
+func (c *fakeConnector) Ping(ctx context.Context) error                         { return nil }
+func (c *fakeConnector) DB() *sql.DB                                            { return nil }
+func (c *fakeConnector) GetCurrentDatabase(ctx context.Context) (string, error) { return "", nil }
+func (c *fakeConnector) Stream(ctx context.Context, query string, args []interface{}, w db.RowWriter) error {
+	return nil
+}
+func (c *fakeConnector) Begin(ctx context.Context) (db.Transaction, error) {
+	return &fakeTx{conn: c}, nil
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		statement string
+		opts      func(*Options)
+		wantRows  [][]interface{}
+		wantErr   bool
+		commits   int
+	}{
+		{
+			name:      "positional mapping",
+			input:     "id,status\n1,active\n2,inactive\n",
+			statement: "INSERT INTO users (id, status) VALUES (?, ?)",
+			opts:      func(o *Options) {},
+			wantRows:  [][]interface{}{{int64(1), "active"}, {int64(2), "inactive"}},
+			commits:   1,
+		},
+		{
+			name:      "mapping by header name",
+			input:     "id,status\n1,active\n2,inactive\n",
+			statement: "UPDATE users SET status = ? WHERE id = ?",
+			opts:      func(o *Options) { o.Columns = []string{"status", "id"} },
+			wantRows:  [][]interface{}{{"active", int64(1)}, {"inactive", int64(2)}},
+			commits:   1,
+		},
+		{
+			name:      "no header",
+			input:     "active\t1\n",
+			statement: "UPDATE users SET status = ? WHERE id = ?",
+			opts: func(o *Options) {
+				o.Header = false
+				o.Delimiter = '\t'
+			},
+			wantRows: [][]interface{}{{"active", int64(1)}},
+			commits:  1,
+		},
+		{
+			name:      "commits per batch",
+			input:     "v\na\nb\nc\n",
+			statement: "INSERT INTO t (v) VALUES (?)",
+			opts:      func(o *Options) { o.BatchSize = 2 },
+			wantRows:  [][]interface{}{{"a"}, {"b"}, {"c"}},
+			commits:   2,
+		},
+		{
+			name:      "skip failing rows",
+			input:     "v\na\nbad\nc\n",
+			statement: "INSERT INTO t (v) VALUES (?)",
+			opts:      func(o *Options) { o.OnError = ErrorSkip },
+			wantRows:  [][]interface{}{{"a"}, {"c"}},
+			commits:   1,
+		},
+		{
+			name:      "skip rows of an aborted transaction",
+			input:     "v\na\ndeadlock\nc\n",
+			statement: "INSERT INTO t (v) VALUES (?)",
+			opts:      func(o *Options) { o.OnError = ErrorSkip },
+			wantRows:  [][]interface{}{{"c"}},
+			commits:   1,
+		},
+		{
+			name:      "stop on failing row",
+			input:     "v\na\nbad\nc\n",
+			statement: "INSERT INTO t (v) VALUES (?)",
+			opts:      func(o *Options) {},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown header column",
+			input:     "v\na\n",
+			statement: "INSERT INTO t (v) VALUES (?)",
+			opts:      func(o *Options) { o.Columns = []string{"missing"} },
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConnector{}
+			opts := DefaultOptions()
+			tt.opts(&opts)
+
+			_, err := Run(context.Background(), conn, strings.NewReader(tt.input), tt.statement, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(conn.committed) != 0 {
+					t.Errorf("committed %v, want nothing", conn.committed)
+				}
+				return
+			}
+			if !reflect.DeepEqual(conn.committed, tt.wantRows) {
+				t.Errorf("committed rows = %v, want %v", conn.committed, tt.wantRows)
+			}
+			if conn.commits != tt.commits {
+				t.Errorf("commits = %d, want %d", conn.commits, tt.commits)
+			}
+		})
+	}
+}
+
+func TestRunReportsRowsOfAbortedTransaction(t *testing.T) {
+	conn := &fakeConnector{}
+	opts := DefaultOptions()
+	opts.OnError = ErrorSkip
+
+	input := "v\na\nb\ndeadlock\nc\n"
+	result, err := Run(context.Background(), conn, strings.NewReader(input), "INSERT INTO t (v) VALUES (?)", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Committed != 1 {
+		t.Errorf("Committed = %d, want 1", result.Committed)
+	}
+	var lines []int
+	for _, f := range result.Failed {
+		if !errors.Is(f.Err, db.ErrTransactionAborted) {
+			t.Errorf("line %d: error = %v, want ErrTransactionAborted", f.Line, f.Err)
+		}
+		lines = append(lines, f.Line)
+	}
+	if want := []int{2, 3, 4}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("failed lines = %v, want %v", lines, want)
+	}
+}
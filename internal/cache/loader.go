@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// fetchTimeout bounds a provider call. Fetches are detached from the
+	// caller's context so that a result arriving after a short completion
+	// timeout still lands in the cache.
+	fetchTimeout = 30 * time.Second
+	// refreshRetry delays the next refresh of a stale entry after a failed one.
+	refreshRetry = 5 * time.Second
+)
+
+type entry[V any] struct {
+	value  V
+	expiry time.Time
+}
+
+// flight is a fetch in progress. done is closed once value and err are set.
+type flight[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// loader holds the entries of one kind of metadata and their in-flight
+// fetches. It is guarded by the owning MetadataCache's mutex. A zero ttl
+// uses the cache's TTL.
+type loader[K comparable, V any] struct {
+	entries  map[K]*entry[V]
+	inflight map[K]*flight[V]
+	ttl      time.Duration
+}
+
+// invalidate drops the entry for key and detaches its in-flight fetch.
+func (l *loader[K, V]) invalidate(key K) {
+	delete(l.entries, key)
+	delete(l.inflight, key)
+}
+
+// peek returns the entry for key, fresh or stale, without fetching it.
+func (l *loader[K, V]) peek(key K) (V, bool) {
+	if e, ok := l.entries[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func newLoader[K comparable, V any]() *loader[K, V] {
+	return &loader[K, V]{
+		entries:  make(map[K]*entry[V]),
+		inflight: make(map[K]*flight[V]),
+	}
+}
+
+// load returns the entry for key. Fresh entries are returned directly;
+// stale entries are returned while a background refresh runs; missing
+// entries are fetched once no matter how many callers ask concurrently.
+// onLoad, if not nil, is called with the mutex held when a fetched value
+// is stored.
+func load[K comparable, V any](c *MetadataCache, l *loader[K, V], ctx context.Context, key K,
+	fetch func(context.Context) (V, error), onLoad func(V)) (V, error) {
+	c.mu.Lock()
+	if e, ok := l.entries[key]; ok {
+		c.stats.Hits++
+		if time.Now().After(e.expiry) && l.inflight[key] == nil {
+			c.stats.Refreshes++
+			startFetch(c, l, key, fetch, onLoad)
+		}
+		value := e.value
+		c.mu.Unlock()
+		return value, nil
+	}
+
+	c.stats.Misses++
+	f := l.inflight[key]
+	if f == nil {
+		f = startFetch(c, l, key, fetch, onLoad)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// startFetch runs fetch in the background. It must be called with the
+// mutex held. Results of fetches started before a Reload or an
+// invalidation of their key are discarded.
+func startFetch[K comparable, V any](c *MetadataCache, l *loader[K, V], key K,
+	fetch func(context.Context) (V, error), onLoad func(V)) *flight[V] {
+	f := &flight[V]{done: make(chan struct{})}
+	l.inflight[key] = f
+	generation := c.generation
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+		value, err := fetch(ctx)
+
+		c.mu.Lock()
+		// The flight is gone when its key was invalidated meanwhile
+		if l.inflight[key] == f && c.generation == generation {
+			switch {
+			case err == nil:
+				ttl := c.ttl
+				if l.ttl > 0 {
+					ttl = l.ttl
+				}
+				l.entries[key] = &entry[V]{value: value, expiry: time.Now().Add(ttl)}
+				if onLoad != nil {
+					onLoad(value)
+				}
+			case l.entries[key] != nil:
+				// Keep serving the stale value and retry later
+				l.entries[key].expiry = time.Now().Add(refreshRetry)
+			}
+		}
+		if l.inflight[key] == f {
+			delete(l.inflight, key)
+		}
+		c.mu.Unlock()
+
+		f.value, f.err = value, err
+		close(f.done)
+	}()
+
+	return f
+}
@@ -8,148 +8,362 @@ import (
 	"github.com/mastar3104/sqcl/internal/db"
 )
 
+// tableKey identifies a table within a schema.
+type tableKey struct {
+	schema string
+	table  string
+}
+
+// columnKey identifies a column of a table.
+type columnKey struct {
+	tableKey
+	column string
+}
+
+const (
+	// valuesTTL is the lifetime of sampled column values, which change far
+	// more often than the schema.
+	valuesTTL = time.Minute
+	// valuesLimit bounds the number of sampled values per column.
+	valuesLimit = 50
+)
+
+// schemaColumns holds the columns of every table in a schema.
+type schemaColumns struct {
+	names  []string
+	tables map[string][]db.ColumnInfo
+}
+
+// Stats counts cache lookups. A hit on an expired entry also counts as a
+// refresh when it starts a background fetch.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Refreshes uint64
+}
+
 // MetadataCache provides TTL-based caching for database metadata.
+// Expired entries are served while they are refreshed in the background,
+// and concurrent misses for the same key share a single fetch.
 type MetadataCache struct {
 	provider db.MetadataProvider
 	ttl      time.Duration
 
-	mu            sync.RWMutex
-	tables        []string
-	tablesExpiry  time.Time
-	columns       map[string][]db.ColumnInfo
-	columnsExpiry map[string]time.Time
-	databases     []string
-	databasesExp  time.Time
+	mu         sync.Mutex
+	generation int
+	stats      Stats
+	path       string
+	checksum   string
+	current    string
+	tables     *loader[string, []string]
+	columns    *loader[tableKey, []db.ColumnInfo]
+	allColumns *loader[string, schemaColumns]
+	indexes    *loader[tableKey, []db.IndexInfo]
+	fks        *loader[tableKey, []db.ForeignKeyInfo]
+	status     *loader[tableKey, *db.TableStatus]
+	databases  *loader[struct{}, []string]
+	values     *loader[columnKey, []string]
+	views      *loader[string, []string]
+	routines   *loader[string, []db.RoutineInfo]
+	triggers   *loader[string, []db.TriggerInfo]
 }
 
 // NewMetadataCache creates a new metadata cache with the specified TTL.
 func NewMetadataCache(provider db.MetadataProvider, ttl time.Duration) *MetadataCache {
-	return &MetadataCache{
-		provider:      provider,
-		ttl:           ttl,
-		columns:       make(map[string][]db.ColumnInfo),
-		columnsExpiry: make(map[string]time.Time),
+	c := &MetadataCache{
+		provider: provider,
+		ttl:      ttl,
 	}
+	c.clear()
+	return c
 }
 
-// GetTables returns cached table names or fetches them if expired.
-func (c *MetadataCache) GetTables(ctx context.Context) ([]string, error) {
-	c.mu.RLock()
-	if time.Now().Before(c.tablesExpiry) && c.tables != nil {
-		tables := c.tables
-		c.mu.RUnlock()
-		return tables, nil
+// clear drops all entries. It must be called with the mutex held.
+func (c *MetadataCache) clear() {
+	c.generation++
+	c.tables = newLoader[string, []string]()
+	c.columns = newLoader[tableKey, []db.ColumnInfo]()
+	c.allColumns = newLoader[string, schemaColumns]()
+	c.indexes = newLoader[tableKey, []db.IndexInfo]()
+	c.fks = newLoader[tableKey, []db.ForeignKeyInfo]()
+	c.status = newLoader[tableKey, *db.TableStatus]()
+	c.databases = newLoader[struct{}, []string]()
+	c.values = newLoader[columnKey, []string]()
+	c.values.ttl = valuesTTL
+	c.views = newLoader[string, []string]()
+	c.routines = newLoader[string, []db.RoutineInfo]()
+	c.triggers = newLoader[string, []db.TriggerInfo]()
+}
+
+// scope resolves an empty schema to the current database. It must be
+// called with the mutex held.
+func (c *MetadataCache) scope(schema string) string {
+	if schema == "" {
+		return c.current
 	}
-	c.mu.RUnlock()
+	return schema
+}
 
+// SetCurrentDatabase sets the database that an empty schema refers to,
+// e.g. after USE. Entries of other databases are kept.
+func (c *MetadataCache) SetCurrentDatabase(name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.current = name
+}
 
-	// Double-check after acquiring write lock
-	if time.Now().Before(c.tablesExpiry) && c.tables != nil {
-		return c.tables, nil
-	}
-
-	tables, err := c.provider.GetTables(ctx)
-	if err != nil {
-		return nil, err
-	}
+// GetTables returns cached table names of a schema or fetches them if missing.
+func (c *MetadataCache) GetTables(ctx context.Context, schema string) ([]string, error) {
+	c.mu.Lock()
+	l := c.tables
+	schema = c.scope(schema)
+	c.mu.Unlock()
 
-	c.tables = tables
-	c.tablesExpiry = time.Now().Add(c.ttl)
-	return tables, nil
+	return load(c, l, ctx, schema, func(ctx context.Context) ([]string, error) {
+		return c.provider.GetTables(ctx, schema)
+	}, nil)
 }
 
-// GetColumns returns cached column info or fetches if expired.
-func (c *MetadataCache) GetColumns(ctx context.Context, tableName string) ([]db.ColumnInfo, error) {
-	c.mu.RLock()
-	if expiry, ok := c.columnsExpiry[tableName]; ok && time.Now().Before(expiry) {
-		cols := c.columns[tableName]
-		c.mu.RUnlock()
-		return cols, nil
-	}
-	c.mu.RUnlock()
+// CachedTables returns the table names of a schema if they are cached,
+// expired or not. It never fetches and does not count as a lookup, so it
+// is safe to call on every keystroke.
+func (c *MetadataCache) CachedTables(schema string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tables.peek(c.scope(schema))
+}
 
+// CachedColumns returns the columns of a table if they are cached, like
+// CachedTables.
+func (c *MetadataCache) CachedColumns(schema, tableName string) ([]db.ColumnInfo, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.columns.peek(tableKey{c.scope(schema), tableName})
+}
 
-	// Double-check after acquiring write lock
-	if expiry, ok := c.columnsExpiry[tableName]; ok && time.Now().Before(expiry) {
-		return c.columns[tableName], nil
-	}
+// GetColumns returns cached column info or fetches it if missing.
+func (c *MetadataCache) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	c.mu.Lock()
+	l := c.columns
+	schema = c.scope(schema)
+	c.mu.Unlock()
 
-	cols, err := c.provider.GetColumns(ctx, tableName)
-	if err != nil {
-		return nil, err
-	}
+	return load(c, l, ctx, tableKey{schema, tableName}, func(ctx context.Context) ([]db.ColumnInfo, error) {
+		return c.provider.GetColumns(ctx, schema, tableName)
+	}, nil)
+}
 
-	c.columns[tableName] = cols
-	c.columnsExpiry[tableName] = time.Now().Add(c.ttl)
-	return cols, nil
+// GetIndexes returns cached index info or fetches it if missing.
+func (c *MetadataCache) GetIndexes(ctx context.Context, schema, tableName string) ([]db.IndexInfo, error) {
+	c.mu.Lock()
+	l := c.indexes
+	schema = c.scope(schema)
+	c.mu.Unlock()
+
+	return load(c, l, ctx, tableKey{schema, tableName}, func(ctx context.Context) ([]db.IndexInfo, error) {
+		return c.provider.GetIndexes(ctx, schema, tableName)
+	}, nil)
 }
 
-// GetDatabases returns cached database names or fetches if expired.
-func (c *MetadataCache) GetDatabases(ctx context.Context) ([]string, error) {
-	c.mu.RLock()
-	if time.Now().Before(c.databasesExp) && c.databases != nil {
-		dbs := c.databases
-		c.mu.RUnlock()
-		return dbs, nil
-	}
-	c.mu.RUnlock()
+// GetForeignKeys returns cached foreign keys or fetches them if missing.
+func (c *MetadataCache) GetForeignKeys(ctx context.Context, schema, tableName string) ([]db.ForeignKeyInfo, error) {
+	c.mu.Lock()
+	l := c.fks
+	schema = c.scope(schema)
+	c.mu.Unlock()
+
+	return load(c, l, ctx, tableKey{schema, tableName}, func(ctx context.Context) ([]db.ForeignKeyInfo, error) {
+		return c.provider.GetForeignKeys(ctx, schema, tableName)
+	}, nil)
+}
 
+// GetTableStatus returns cached table statistics or fetches them if missing.
+func (c *MetadataCache) GetTableStatus(ctx context.Context, schema, tableName string) (*db.TableStatus, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	l := c.status
+	schema = c.scope(schema)
+	c.mu.Unlock()
 
-	// Double-check after acquiring write lock
-	if time.Now().Before(c.databasesExp) && c.databases != nil {
-		return c.databases, nil
-	}
+	return load(c, l, ctx, tableKey{schema, tableName}, func(ctx context.Context) (*db.TableStatus, error) {
+		return c.provider.GetTableStatus(ctx, schema, tableName)
+	}, nil)
+}
 
-	dbs, err := c.provider.GetDatabases(ctx)
-	if err != nil {
-		return nil, err
-	}
+// GetColumnValues returns a sample of the distinct values of a column,
+// cached for a short time.
+func (c *MetadataCache) GetColumnValues(ctx context.Context, schema, tableName, column string) ([]string, error) {
+	c.mu.Lock()
+	l := c.values
+	schema = c.scope(schema)
+	c.mu.Unlock()
+
+	key := columnKey{tableKey{schema, tableName}, column}
+	return load(c, l, ctx, key, func(ctx context.Context) ([]string, error) {
+		return c.provider.GetColumnValues(ctx, schema, tableName, column, valuesLimit)
+	}, nil)
+}
+
+// GetViews returns cached view names of a schema or fetches them if missing.
+func (c *MetadataCache) GetViews(ctx context.Context, schema string) ([]string, error) {
+	c.mu.Lock()
+	l := c.views
+	schema = c.scope(schema)
+	c.mu.Unlock()
 
-	c.databases = dbs
-	c.databasesExp = time.Now().Add(c.ttl)
-	return dbs, nil
+	return load(c, l, ctx, schema, func(ctx context.Context) ([]string, error) {
+		return c.provider.GetViews(ctx, schema)
+	}, nil)
 }
 
-// GetAllColumns returns all cached columns for all known tables.
+// GetRoutines returns cached stored procedures and functions of a schema
+// or fetches them if missing.
+func (c *MetadataCache) GetRoutines(ctx context.Context, schema string) ([]db.RoutineInfo, error) {
+	c.mu.Lock()
+	l := c.routines
+	schema = c.scope(schema)
+	c.mu.Unlock()
+
+	return load(c, l, ctx, schema, func(ctx context.Context) ([]db.RoutineInfo, error) {
+		return c.provider.GetRoutines(ctx, schema)
+	}, nil)
+}
+
+// GetTriggers returns cached triggers of a schema or fetches them if missing.
+func (c *MetadataCache) GetTriggers(ctx context.Context, schema string) ([]db.TriggerInfo, error) {
+	c.mu.Lock()
+	l := c.triggers
+	schema = c.scope(schema)
+	c.mu.Unlock()
+
+	return load(c, l, ctx, schema, func(ctx context.Context) ([]db.TriggerInfo, error) {
+		return c.provider.GetTriggers(ctx, schema)
+	}, nil)
+}
+
+// GetDatabases returns cached database names or fetches them if missing.
+func (c *MetadataCache) GetDatabases(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	l := c.databases
+	c.mu.Unlock()
+
+	return load(c, l, ctx, struct{}{}, func(ctx context.Context) ([]string, error) {
+		return c.provider.GetDatabases(ctx)
+	}, nil)
+}
+
+// GetAllColumns returns the distinct column names of all tables in the
+// current database. The columns of every table are loaded with a single
+// query, which also fills the per-table cache.
 func (c *MetadataCache) GetAllColumns(ctx context.Context) ([]string, error) {
-	tables, err := c.GetTables(ctx)
+	c.mu.Lock()
+	l := c.allColumns
+	schema := c.scope("")
+	c.mu.Unlock()
+
+	all, err := load(c, l, ctx, schema, func(ctx context.Context) (schemaColumns, error) {
+		tables, err := c.provider.GetAllColumns(ctx, schema)
+		if err != nil {
+			return schemaColumns{}, err
+		}
+
+		columnSet := make(map[string]struct{})
+		for _, cols := range tables {
+			for _, col := range cols {
+				columnSet[col.Name] = struct{}{}
+			}
+		}
+		names := make([]string, 0, len(columnSet))
+		for name := range columnSet {
+			names = append(names, name)
+		}
+		return schemaColumns{names: names, tables: tables}, nil
+	}, func(all schemaColumns) {
+		expiry := time.Now().Add(c.ttl)
+		for table, cols := range all.tables {
+			c.columns.entries[tableKey{schema, table}] = &entry[[]db.ColumnInfo]{value: cols, expiry: expiry}
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
+	return all.names, nil
+}
 
-	columnSet := make(map[string]struct{})
-	for _, table := range tables {
-		cols, err := c.GetColumns(ctx, table)
-		if err != nil {
-			continue
+// GetDDL returns the definition of an object. Definitions are not cached
+// so that the output always reflects the current schema.
+func (c *MetadataCache) GetDDL(ctx context.Context, objectType db.ObjectType, schema, name string) (*db.ObjectDDL, error) {
+	return c.provider.GetDDL(ctx, objectType, schema, name)
+}
+
+// InvalidateTable drops the cached metadata of a table together with the
+// table list and column names of its schema.
+func (c *MetadataCache) InvalidateTable(schema, tableName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema = c.scope(schema)
+	key := tableKey{schema, tableName}
+	c.columns.invalidate(key)
+	c.indexes.invalidate(key)
+	c.status.invalidate(key)
+	for k := range c.values.entries {
+		if k.tableKey == key {
+			c.values.invalidate(k)
 		}
-		for _, col := range cols {
-			columnSet[col.Name] = struct{}{}
+	}
+	for k := range c.values.inflight {
+		if k.tableKey == key {
+			c.values.invalidate(k)
 		}
 	}
+	c.tables.invalidate(schema)
+	c.allColumns.invalidate(schema)
+	// Foreign keys of other tables may reference this one
+	c.fks = newLoader[tableKey, []db.ForeignKeyInfo]()
+}
 
-	columns := make([]string, 0, len(columnSet))
-	for col := range columnSet {
-		columns = append(columns, col)
-	}
-	return columns, nil
+// InvalidateTableList drops the cached table names of a schema.
+func (c *MetadataCache) InvalidateTableList(schema string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema = c.scope(schema)
+	c.tables.invalidate(schema)
+	c.allColumns.invalidate(schema)
+}
+
+// InvalidateObjects drops the cached views, routines and triggers of a
+// schema.
+func (c *MetadataCache) InvalidateObjects(schema string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema = c.scope(schema)
+	c.views.invalidate(schema)
+	c.routines.invalidate(schema)
+	c.triggers.invalidate(schema)
+}
+
+// InvalidateDatabases drops the cached database names.
+func (c *MetadataCache) InvalidateDatabases() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.databases.invalidate(struct{}{})
+}
+
+// Stats returns the lookup counters.
+func (c *MetadataCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
 }
 
 // Reload clears all cached data, forcing refresh on next access.
+// Fetches still in flight are discarded when they complete.
 func (c *MetadataCache) Reload() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.tables = nil
-	c.tablesExpiry = time.Time{}
-	c.columns = make(map[string][]db.ColumnInfo)
-	c.columnsExpiry = make(map[string]time.Time)
-	c.databases = nil
-	c.databasesExp = time.Time{}
+	c.clear()
 }
@@ -0,0 +1,405 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// fakeProvider serves fixed columns and counts the calls per method.
+// GetColumns for a table listed in block waits until its channel is closed.
+type fakeProvider struct {
+	mu      sync.Mutex
+	columns map[string][]db.ColumnInfo
+	calls   map[string]int
+	block   map[string]chan struct{}
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{
+		columns: map[string][]db.ColumnInfo{
+			"users":  {{Name: "id"}, {Name: "name"}},
+			"orders": {{Name: "id"}, {Name: "user_id"}},
+		},
+		calls: make(map[string]int),
+		block: make(map[string]chan struct{}),
+	}
+}
+
+func (p *fakeProvider) called(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[method]
+}
+
+func (p *fakeProvider) record(method string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[method]++
+}
+
+func (p *fakeProvider) GetTables(ctx context.Context, schema string) ([]string, error) {
+	p.record("GetTables")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var tables []string
+	for t := range p.columns {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+func (p *fakeProvider) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	p.record("GetColumns")
+	p.mu.Lock()
+	block := p.block[tableName]
+	cols := p.columns[tableName]
+	p.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+	return cols, nil
+}
+
+func (p *fakeProvider) GetAllColumns(ctx context.Context, schema string) (map[string][]db.ColumnInfo, error) {
+	p.record("GetAllColumns")
+	return p.columns, nil
+}
+
+func (p *fakeProvider) GetSchemaChecksum(ctx context.Context, schema string) (string, error) {
+	p.record("GetSchemaChecksum")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fmt.Sprintf("%d", len(p.columns)), nil
+}
+
+func (p *fakeProvider) GetDatabases(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *fakeProvider) GetIndexes(ctx context.Context, schema, tableName string) ([]db.IndexInfo, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetForeignKeys(ctx context.Context, schema, tableName string) ([]db.ForeignKeyInfo, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetViews(ctx context.Context, schema string) ([]string, error) {
+	p.record("GetViews")
+	return []string{schema + "_view"}, nil
+}
+func (p *fakeProvider) GetRoutines(ctx context.Context, schema string) ([]db.RoutineInfo, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetTriggers(ctx context.Context, schema string) ([]db.TriggerInfo, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetTableStatus(ctx context.Context, schema, tableName string) (*db.TableStatus, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetDDL(ctx context.Context, objectType db.ObjectType, schema, name string) (*db.ObjectDDL, error) {
+	return nil, nil
+}
+func (p *fakeProvider) GetColumnValues(ctx context.Context, schema, tableName, column string, limit int) ([]string, error) {
+	p.record("GetColumnValues")
+	return []string{"paid", "shipped"}, nil
+}
+
+func TestGetAllColumnsFillsTableCache(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+
+	columns, err := c.GetAllColumns(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(columns)
+	want := []string{"id", "name", "user_id"}
+	if len(columns) != len(want) {
+		t.Fatalf("GetAllColumns() = %v, want %v", columns, want)
+	}
+	for i := range want {
+		if columns[i] != want[i] {
+			t.Fatalf("GetAllColumns() = %v, want %v", columns, want)
+		}
+	}
+
+	if _, err := c.GetAllColumns(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cols, err := c.GetColumns(ctx, "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 2 || cols[1].Name != "user_id" {
+		t.Errorf("GetColumns(orders) = %v", cols)
+	}
+
+	if n := provider.called("GetAllColumns"); n != 1 {
+		t.Errorf("GetAllColumns called %d times, want 1", n)
+	}
+	if n := provider.called("GetColumns"); n != 0 {
+		t.Errorf("GetColumns called %d times, want 0", n)
+	}
+}
+
+func TestGetColumnsSingleFlight(t *testing.T) {
+	provider := newFakeProvider()
+	release := make(chan struct{})
+	provider.block["users"] = release
+	c := NewMetadataCache(provider, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetColumns(context.Background(), "", "users"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// Wait until every caller has registered its miss
+	for c.Stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := provider.called("GetColumns"); n != 1 {
+		t.Errorf("GetColumns called %d times, want 1", n)
+	}
+}
+
+func TestSlowFetchDoesNotBlockOtherKeys(t *testing.T) {
+	provider := newFakeProvider()
+	release := make(chan struct{})
+	defer close(release)
+	provider.block["users"] = release
+	c := NewMetadataCache(provider, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetColumns(ctx, "", "users"); err != context.DeadlineExceeded {
+		t.Fatalf("GetColumns(users) error = %v, want deadline exceeded", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := c.GetColumns(context.Background(), "", "orders"); err != nil {
+			t.Error(err)
+		}
+		if _, err := c.GetTables(context.Background(), ""); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lookups blocked behind a slow fetch")
+	}
+}
+
+func TestStaleEntryRefreshedInBackground(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, 10*time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The stale value is returned immediately while a refresh starts
+	provider.mu.Lock()
+	provider.columns["payments"] = nil
+	provider.mu.Unlock()
+	tables, err := c.GetTables(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Errorf("stale GetTables() = %v, want the previous two tables", tables)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		tables, _ = c.GetTables(ctx, "")
+		if len(tables) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetTables() = %v after refresh, want three tables", tables)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := c.Stats()
+	if stats.Misses != 1 || stats.Refreshes < 1 {
+		t.Errorf("Stats() = %+v, want 1 miss and at least 1 refresh", stats)
+	}
+}
+
+func TestInvalidateTable(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetColumns(ctx, "", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetColumns(ctx, "", "orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.InvalidateTable("", "users")
+
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetColumns(ctx, "", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetColumns(ctx, "", "orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := provider.called("GetTables"); n != 2 {
+		t.Errorf("GetTables called %d times, want 2", n)
+	}
+	// Only users is fetched again
+	if n := provider.called("GetColumns"); n != 3 {
+		t.Errorf("GetColumns called %d times, want 3", n)
+	}
+}
+
+func TestCurrentDatabaseScope(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+
+	c.SetCurrentDatabase("app")
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	// The same database addressed explicitly is a hit
+	if _, err := c.GetTables(ctx, "app"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetCurrentDatabase("shop")
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := provider.called("GetTables"); n != 2 {
+		t.Errorf("GetTables called %d times, want 2", n)
+	}
+}
+
+func TestColumnValuesUseShortTTL(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Hour)
+	ctx := context.Background()
+
+	values, err := c.GetColumnValues(ctx, "", "orders", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 {
+		t.Errorf("GetColumnValues() = %v", values)
+	}
+
+	c.mu.Lock()
+	e := c.values.entries[columnKey{tableKey{"", "orders"}, "status"}]
+	c.mu.Unlock()
+	if e == nil || time.Until(e.expiry) > valuesTTL {
+		t.Errorf("sampled values cached beyond %v", valuesTTL)
+	}
+
+	c.InvalidateTable("", "orders")
+	if _, err := c.GetColumnValues(ctx, "", "orders", "status"); err != nil {
+		t.Fatal(err)
+	}
+	if n := provider.called("GetColumnValues"); n != 2 {
+		t.Errorf("GetColumnValues called %d times, want 2", n)
+	}
+}
+
+func TestCachedLookupsNeverFetch(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+
+	if _, ok := c.CachedTables(""); ok {
+		t.Error("CachedTables() reported tables before any were loaded")
+	}
+	if _, ok := c.CachedColumns("", "users"); ok {
+		t.Error("CachedColumns() reported columns before any were loaded")
+	}
+	if n := provider.called("GetTables") + provider.called("GetColumns"); n != 0 {
+		t.Errorf("provider called %d times, want 0", n)
+	}
+
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAllColumns(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tables, ok := c.CachedTables("")
+	if !ok || len(tables) != 2 {
+		t.Errorf("CachedTables() = %v, %v", tables, ok)
+	}
+	cols, ok := c.CachedColumns("", "orders")
+	if !ok || len(cols) != 2 {
+		t.Errorf("CachedColumns(orders) = %v, %v", cols, ok)
+	}
+	if stats := c.Stats(); stats.Hits != 0 {
+		t.Errorf("cached lookups counted as %d hits", stats.Hits)
+	}
+}
+
+func TestObjectsKeyedBySchema(t *testing.T) {
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+
+	c.SetCurrentDatabase("app")
+	if _, err := c.GetViews(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	c.SetCurrentDatabase("shop")
+	views, err := c.GetViews(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 1 || views[0] != "shop_view" {
+		t.Errorf("GetViews() after switching database = %v, want [shop_view]", views)
+	}
+	if _, err := c.GetViews(ctx, "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if n := provider.called("GetViews"); n != 2 {
+		t.Errorf("GetViews called %d times, want 2", n)
+	}
+
+	c.InvalidateObjects("")
+	if _, err := c.GetViews(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetViews(ctx, "app"); err != nil {
+		t.Fatal(err)
+	}
+	if n := provider.called("GetViews"); n != 3 {
+		t.Errorf("GetViews called %d times after invalidation, want 3", n)
+	}
+}
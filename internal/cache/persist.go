@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// snapshotVersion is bumped when the file layout changes; files with
+// another version are ignored.
+const snapshotVersion = 1
+
+// snapshot is the on-disk form of the metadata of the current database.
+// Only the database selected when the cache is saved is persisted.
+type snapshot struct {
+	Version   int                        `json:"version"`
+	SavedAt   time.Time                  `json:"saved_at"`
+	Checksum  string                     `json:"checksum"`
+	Database  string                     `json:"database"`
+	Tables    []string                   `json:"tables"`
+	Columns   map[string][]db.ColumnInfo `json:"columns"`
+	Databases []string                   `json:"databases,omitempty"`
+}
+
+// GetCacheFilePath returns the path of the persistent cache for a saved connection.
+func GetCacheFilePath(connectionName string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(connectionName) + ".json"
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".sqcl", "cache", name)
+	}
+	return filepath.Join(home, ".sqcl", "cache", name)
+}
+
+// Persist associates the cache with a file and loads its contents, if any.
+// Loaded entries are served as fresh until Revalidate finds the schema changed.
+func (c *MetadataCache) Persist(path string) error {
+	c.mu.Lock()
+	c.path = path
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Ignore snapshots of another layout or another default database
+	if snap.Version != snapshotVersion || snap.Database != c.current {
+		return nil
+	}
+	schema := c.current
+
+	expiry := time.Now().Add(c.ttl)
+	if snap.Tables != nil {
+		c.tables.entries[schema] = &entry[[]string]{value: snap.Tables, expiry: expiry}
+	}
+	if snap.Columns != nil {
+		all := schemaColumns{tables: snap.Columns}
+		seen := make(map[string]struct{})
+		for table, cols := range snap.Columns {
+			c.columns.entries[tableKey{schema, table}] = &entry[[]db.ColumnInfo]{value: cols, expiry: expiry}
+			for _, col := range cols {
+				if _, ok := seen[col.Name]; !ok {
+					seen[col.Name] = struct{}{}
+					all.names = append(all.names, col.Name)
+				}
+			}
+		}
+		c.allColumns.entries[schema] = &entry[schemaColumns]{value: all, expiry: expiry}
+	}
+	if snap.Databases != nil {
+		c.databases.entries[struct{}{}] = &entry[[]string]{value: snap.Databases, expiry: expiry}
+	}
+	c.checksum = snap.Checksum
+	return nil
+}
+
+// Revalidate compares the schema checksum with the one the cache was
+// loaded with. When they differ, the cache is cleared and tables and
+// columns are fetched again.
+func (c *MetadataCache) Revalidate(ctx context.Context) error {
+	// The checksum is taken before fetching, so a change made in between
+	// is detected on the next start rather than missed.
+	c.mu.Lock()
+	schema := c.scope("")
+	c.mu.Unlock()
+
+	checksum, err := c.provider.GetSchemaChecksum(ctx, schema)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	unchanged := checksum == c.checksum
+	c.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	c.Reload()
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		return err
+	}
+	if _, err := c.GetAllColumns(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.checksum = checksum
+	c.mu.Unlock()
+	return nil
+}
+
+// Save writes the tables and columns of the current database to the
+// persistent cache file. It does nothing when Persist was not called.
+func (c *MetadataCache) Save() error {
+	c.mu.Lock()
+	path := c.path
+	snap := snapshot{
+		Version:  snapshotVersion,
+		SavedAt:  time.Now(),
+		Checksum: c.checksum,
+		Database: c.current,
+		Columns:  make(map[string][]db.ColumnInfo),
+	}
+	if e, ok := c.tables.entries[c.current]; ok {
+		snap.Tables = e.value
+	}
+	for key, e := range c.columns.entries {
+		if key.schema == c.current {
+			snap.Columns[key.table] = e.value
+		}
+	}
+	if e, ok := c.databases.entries[struct{}{}]; ok {
+		snap.Databases = e.value
+	}
+	c.mu.Unlock()
+
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn cache
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Wipe clears the cache and deletes its persistent file.
+func (c *MetadataCache) Wipe() error {
+	c.mu.Lock()
+	path := c.path
+	c.checksum = ""
+	c.mu.Unlock()
+
+	c.Reload()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPersistRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "dev.json")
+	ctx := context.Background()
+
+	provider := newFakeProvider()
+	c := NewMetadataCache(provider, time.Minute)
+	if err := c.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Revalidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new cache serves the saved metadata without fetching it again
+	provider = newFakeProvider()
+	c = NewMetadataCache(provider, time.Minute)
+	if err := c.Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Revalidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := c.GetTables(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols, err := c.GetColumns(ctx, "", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || len(cols) != 2 {
+		t.Errorf("tables = %v, users columns = %v", tables, cols)
+	}
+	if n := provider.called("GetTables") + provider.called("GetAllColumns") + provider.called("GetColumns"); n != 0 {
+		t.Errorf("%d fetches after loading an unchanged cache, want 0", n)
+	}
+
+	// A changed checksum refetches
+	provider.columns["payments"] = nil
+	if err := c.Revalidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := provider.called("GetAllColumns"); n != 1 {
+		t.Errorf("GetAllColumns called %d times after a schema change, want 1", n)
+	}
+
+	if err := c.Wipe(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Wipe: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// KeywordCase selects the case of completed keywords and function names.
+type KeywordCase int
+
+const (
+	// CasePreserve follows the case of the typed word: lower case when it
+	// starts with a lower-case letter, upper case otherwise.
+	CasePreserve KeywordCase = iota
+	// CaseUpper always completes keywords in upper case.
+	CaseUpper
+	// CaseLower always completes keywords in lower case.
+	CaseLower
+)
+
+// ParseKeywordCase parses a keyword case name.
+func ParseKeywordCase(s string) (KeywordCase, error) {
+	switch strings.ToLower(s) {
+	case "preserve":
+		return CasePreserve, nil
+	case "upper":
+		return CaseUpper, nil
+	case "lower":
+		return CaseLower, nil
+	default:
+		return CasePreserve, fmt.Errorf("unknown keyword case: %s (use upper, lower or preserve)", s)
+	}
+}
+
+// String returns the name of the case.
+func (k KeywordCase) String() string {
+	switch k {
+	case CaseUpper:
+		return "upper"
+	case CaseLower:
+		return "lower"
+	}
+	return "preserve"
+}
+
+// Apply returns keyword in the configured case for the typed word.
+func (k KeywordCase) Apply(keyword, typed string) string {
+	lower := k == CaseLower
+	if k == CasePreserve {
+		for _, r := range typed {
+			lower = unicode.IsLower(r)
+			break
+		}
+	}
+	if lower {
+		return strings.ToLower(keyword)
+	}
+	return strings.ToUpper(keyword)
+}
+
+// QuoteName quotes an identifier when it is a reserved word or contains
+// characters that are not allowed in an unquoted identifier.
+func QuoteName(dialect db.Dialect, name string) string {
+	if name == "" || dialect.IsReserved(name) || !isPlainIdentifier(name) {
+		return dialect.QuoteIdentifier(name)
+	}
+	return name
+}
+
+// isPlainIdentifier reports whether name can be written unquoted: letters,
+// digits, _ and $, and not only digits.
+func isPlainIdentifier(name string) bool {
+	digits := true
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+			digits = false
+		case r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return !digits
+}
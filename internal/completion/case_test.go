@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+func TestKeywordCaseApply(t *testing.T) {
+	tests := []struct {
+		keywordCase KeywordCase
+		keyword     string
+		typed       string
+		want        string
+	}{
+		{CasePreserve, "SELECT", "sel", "select"},
+		{CasePreserve, "SELECT", "Sel", "SELECT"},
+		{CasePreserve, "SELECT", "", "SELECT"},
+		{CasePreserve, "COUNT(", "co", "count("},
+		{CaseUpper, "SELECT", "sel", "SELECT"},
+		{CaseLower, "SELECT", "SEL", "select"},
+		{CaseLower, "SELECT", "", "select"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.keywordCase.Apply(tt.keyword, tt.typed); got != tt.want {
+			t.Errorf("%s.Apply(%q, %q) = %q, want %q", tt.keywordCase, tt.keyword, tt.typed, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeywordCase(t *testing.T) {
+	for _, name := range []string{"upper", "lower", "preserve"} {
+		k, err := ParseKeywordCase(name)
+		if err != nil || k.String() != name {
+			t.Errorf("ParseKeywordCase(%q) = %v, %v", name, k, err)
+		}
+	}
+	if _, err := ParseKeywordCase("title"); err == nil {
+		t.Error("ParseKeywordCase(title) succeeded")
+	}
+}
+
+func TestQuoteName(t *testing.T) {
+	dialect := mysql.NewDialect()
+	tests := map[string]string{
+		"users":      "users",
+		"UserName":   "UserName",
+		"order":      "`order`",
+		"Group":      "`Group`",
+		"first name": "`first name`",
+		"a-b":        "`a-b`",
+		"123":        "`123`",
+		"x`y":        "`x``y`",
+		"price$":     "price$",
+	}
+
+	for name, want := range tests {
+		if got := QuoteName(dialect, name); got != want {
+			t.Errorf("QuoteName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestStyledCandidates(t *testing.T) {
+	c := &SQLCompleter{dialect: mysql.NewDialect()}
+	candidates := []Candidate{
+		{Text: "SELECT", Kind: KindKeyword},
+		{Text: "order", Kind: KindTable},
+		{Text: "orders", Kind: KindTable},
+	}
+
+	got := c.styled(candidates, "or")
+	want := []string{"select", "`order`", "orders"}
+	for i := range want {
+		if got[i].Text != want[i] {
+			t.Errorf("styled()[%d] = %q, want %q", i, got[i].Text, want[i])
+		}
+	}
+
+	matches := Rank("or", got, MatchPrefix)
+	if len(matches) != 2 || matches[0] != "`order`" {
+		t.Errorf("Rank(or) = %q, want the quoted name to match", matches)
+	}
+	if extendWord("or", matches) {
+		t.Error("extendWord() = true for a quoted name")
+	}
+}
@@ -0,0 +1,73 @@
+package completion
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Command describes an internal command for completion.
+type Command struct {
+	Names []string
+	// Tables is set when the first argument is a table name.
+	Tables bool
+	// Args returns the candidates for the argument following args, the
+	// arguments typed so far. It may be nil.
+	Args func(args []string) []string
+}
+
+// SetCommands sets the internal commands completed after ":".
+func (c *SQLCompleter) SetCommands(commands []Command) {
+	c.commands = commands
+}
+
+// commandCandidates completes an internal command line: the command name
+// first, then its arguments. It also returns the word being completed,
+// which unlike in SQL may contain "-", as in exec-batch or --hard.
+func (c *SQLCompleter) commandCandidates(ctx context.Context, input string) ([]Candidate, string) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimLeftFunc(input, unicode.IsSpace), ":"))
+	typing := len(input) > 0 && !unicode.IsSpace(rune(input[len(input)-1]))
+	var word string
+	if typing && len(fields) > 0 {
+		word = fields[len(fields)-1]
+	}
+
+	if len(fields) == 0 || (len(fields) == 1 && typing) {
+		var names []string
+		for _, cmd := range c.commands {
+			names = append(names, cmd.Names...)
+		}
+		return candidatesOf(KindKeyword, names), word
+	}
+
+	name := strings.ToLower(fields[0])
+	args := fields[1:]
+	if typing {
+		args = args[:len(args)-1]
+	}
+	for _, cmd := range c.commands {
+		if !hasName(cmd.Names, name) {
+			continue
+		}
+		if cmd.Tables && len(args) == 0 {
+			// Tables may be qualified, as in shop.orders
+			if qualifier := GetQualifier(input); qualifier != "" {
+				return candidatesOf(KindTable, c.getSchemaTableCandidates(ctx, qualifier)), GetLastWord(input)
+			}
+			return candidatesOf(KindTable, c.getTableCandidates(ctx)), word
+		}
+		if cmd.Args != nil {
+			return candidatesOf(KindKeyword, cmd.Args(args)), word
+		}
+	}
+	return nil, word
+}
+
+func hasName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package completion
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCommandCandidates(t *testing.T) {
+	c := &SQLCompleter{}
+	c.SetCommands([]Command{
+		{Names: []string{"columns", "cols"}, Tables: true},
+		{Names: []string{"connect"}, Args: func(args []string) []string {
+			if len(args) > 0 {
+				return nil
+			}
+			return []string{"prod", "staging"}
+		}},
+		{Names: []string{"exec-batch"}},
+		{Names: []string{"set"}, Args: func(args []string) []string {
+			if len(args) == 1 && args[0] == "completion" {
+				return []string{"fuzzy", "prefix"}
+			}
+			return []string{"completion"}
+		}},
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		word     string
+		expected []string
+	}{
+		{
+			name:     "command names",
+			input:    ":co",
+			word:     "co",
+			expected: []string{"cols", "columns", "connect", "exec-batch", "set"},
+		},
+		{
+			name:     "names with a dash",
+			input:    ":exec-b",
+			word:     "exec-b",
+			expected: []string{"cols", "columns", "connect", "exec-batch", "set"},
+		},
+		{
+			name:     "first argument",
+			input:    ":connect ",
+			word:     "",
+			expected: []string{"prod", "staging"},
+		},
+		{
+			name:     "partial argument",
+			input:    ":connect pr",
+			word:     "pr",
+			expected: []string{"prod", "staging"},
+		},
+		{
+			name:     "second argument",
+			input:    ":set completion f",
+			word:     "f",
+			expected: []string{"fuzzy", "prefix"},
+		},
+		{
+			name:     "command without arguments",
+			input:    ":exec-batch ",
+			word:     "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, word := c.commandCandidates(context.Background(), tt.input)
+			var texts []string
+			for _, candidate := range candidates {
+				texts = append(texts, candidate.Text)
+			}
+			sort.Strings(texts)
+			if word != tt.word || !reflect.DeepEqual(texts, tt.expected) {
+				t.Errorf("commandCandidates(%q) = %q, %q, want %q, %q", tt.input, texts, word, tt.expected, tt.word)
+			}
+		})
+	}
+}
+
+func TestIsCommandLine(t *testing.T) {
+	c := &SQLCompleter{}
+	if !c.isCommandLine(" :desc") {
+		t.Error("expected a command line")
+	}
+	if c.isCommandLine("SELECT ':x'") {
+		t.Error("SQL taken for a command line")
+	}
+
+	c.SetBuffer(func() string { return "SELECT *" })
+	if c.isCommandLine(":x") {
+		t.Error("continuation line taken for a command line")
+	}
+}
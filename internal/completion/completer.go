@@ -13,8 +13,22 @@ import (
 
 // SQLCompleter implements readline.AutoCompleter for SQL input.
 type SQLCompleter struct {
-	cache   *cache.MetadataCache
-	dialect db.Dialect
+	cache    *cache.MetadataCache
+	dialect  db.Dialect
+	buffer   func() string
+	mode     MatchMode
+	keywords KeywordCase
+	pending  *replacement
+	commands []Command
+}
+
+// replacement records a fuzzy completion offered for a line, so that the
+// typed word can be swapped for the candidate once readline inserts it.
+type replacement struct {
+	line       []rune
+	pos        int
+	wordLen    int
+	candidates []string
 }
 
 // NewSQLCompleter creates a new SQL completer.
@@ -25,76 +39,337 @@ func NewSQLCompleter(cache *cache.MetadataCache, dialect db.Dialect) *SQLComplet
 	}
 }
 
+// SetSource replaces the metadata cache and dialect, e.g. after
+// switching connections.
+func (c *SQLCompleter) SetSource(cache *cache.MetadataCache, dialect db.Dialect) {
+	c.cache = cache
+	c.dialect = dialect
+}
+
+// SetBuffer sets the source of the lines already entered for the current
+// statement, so that tables named on earlier lines are taken into account.
+func (c *SQLCompleter) SetBuffer(buffer func() string) {
+	c.buffer = buffer
+}
+
+// SetMatchMode sets how candidates are matched against the typed word.
+func (c *SQLCompleter) SetMatchMode(mode MatchMode) {
+	c.mode = mode
+}
+
+// MatchMode returns the current match mode.
+func (c *SQLCompleter) MatchMode() MatchMode {
+	return c.mode
+}
+
+// SetKeywordCase sets the case of completed keywords.
+func (c *SQLCompleter) SetKeywordCase(k KeywordCase) {
+	c.keywords = k
+}
+
+// KeywordCase returns the case of completed keywords.
+func (c *SQLCompleter) KeywordCase() KeywordCase {
+	return c.keywords
+}
+
 // Do implements readline.AutoCompleter interface.
 func (c *SQLCompleter) Do(line []rune, pos int) ([][]rune, int) {
 	input := string(line[:pos])
+	if c.isCommandLine(input) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		candidates, word := c.commandCandidates(ctx, input)
+		return c.result(line, pos, word, candidates)
+	}
+
+	// Tables may be named after the cursor, as in "SELECT u.| FROM users u"
+	statement := string(line)
+	cursor := pos
+	if c.buffer != nil {
+		if previous := c.buffer(); previous != "" {
+			statement = previous + "\n" + statement
+			cursor += len([]rune(previous)) + 1
+		}
+	}
+	scope := ParseScope(statement, cursor)
+	tables := scope.Tables
+
 	lastWord := GetLastWord(input)
-	lastWordLower := strings.ToLower(lastWord)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
 
-	completionCtx := DetectContext(input)
-	var candidates []string
-
-	switch completionCtx {
-	case ContextTable:
-		candidates = c.getTableCandidates(ctx)
-	case ContextColumn:
-		candidates = c.getColumnCandidates(ctx, input)
-		candidates = append(candidates, c.getKeywordCandidates()...)
-	case ContextDatabase:
-		candidates = c.getDatabaseCandidates(ctx)
+	if target, ok := DetectValueTarget(input); ok {
+		values := c.getValueCandidates(ctx, tables, target)
+		if len(values) > 0 || target.Quote != 0 {
+			return c.result(line, pos, target.Prefix, candidatesOf(KindValue, values))
+		}
+	}
+
+	completionCtx := scope.Context()
+	qualifier := GetQualifier(input)
+	var candidates []Candidate
+
+	switch {
+	case qualifier != "" && completionCtx == ContextColumn:
+		candidates = candidatesOf(KindScopeColumn, c.getQualifiedColumnCandidates(ctx, tables, qualifier))
+	case qualifier != "":
+		// db. is followed by a table of that database
+		candidates = candidatesOf(KindTable, c.getSchemaTableCandidates(ctx, qualifier))
+	case completionCtx == ContextTable:
+		candidates = candidatesOf(KindTable, c.getTableCandidates(ctx))
+	case completionCtx == ContextColumn:
+		if scope.Joined != nil {
+			candidates = candidatesOf(KindJoin, c.getJoinCandidates(ctx, *scope.Joined, tables))
+		}
+		candidates = append(candidates, candidatesOf(KindScopeColumn, c.getColumnCandidates(ctx, tables))...)
+		if c.mode == MatchFuzzy && len(tables) > 0 {
+			// Columns of other tables rank below those in scope
+			candidates = append(candidates, candidatesOf(KindColumn, c.getAllColumnCandidates(ctx))...)
+		}
+		candidates = append(candidates, candidatesOf(KindFunction, c.getFunctionCandidates())...)
+		candidates = append(candidates, candidatesOf(KindKeyword, c.getKeywordCandidates())...)
+	case completionCtx == ContextDatabase:
+		candidates = candidatesOf(KindDatabase, c.getDatabaseCandidates(ctx))
 	default:
 		// Keywords + tables
-		candidates = c.getKeywordCandidates()
-		candidates = append(candidates, c.getTableCandidates(ctx)...)
+		candidates = candidatesOf(KindKeyword, c.getKeywordCandidates())
+		candidates = append(candidates, candidatesOf(KindTable, c.getTableCandidates(ctx))...)
 	}
 
-	// Filter candidates by prefix
-	var matches []string
-	for _, candidate := range candidates {
-		candidateLower := strings.ToLower(candidate)
-		if strings.HasPrefix(candidateLower, lastWordLower) {
-			matches = append(matches, candidate)
+	return c.result(line, pos, lastWord, c.styled(candidates, lastWord))
+}
+
+// styled writes keywords in the configured case and quotes identifiers
+// that need it, or all of them once a backquote has been typed.
+func (c *SQLCompleter) styled(candidates []Candidate, word string) []Candidate {
+	quoted := strings.HasPrefix(word, "`")
+	for i, candidate := range candidates {
+		switch candidate.Kind {
+		case KindKeyword, KindFunction:
+			candidates[i].Text = c.keywords.Apply(candidate.Text, word)
+		case KindScopeColumn, KindColumn, KindTable, KindDatabase:
+			if quoted {
+				candidates[i].Text = c.dialect.QuoteIdentifier(candidate.Text)
+			} else {
+				candidates[i].Text = QuoteName(c.dialect, candidate.Text)
+			}
 		}
 	}
+	return candidates
+}
 
-	// Sort and deduplicate
-	matches = uniqueSorted(matches)
+// isCommandLine reports whether input starts an internal command. Commands
+// are only recognized at the start of a statement.
+func (c *SQLCompleter) isCommandLine(input string) bool {
+	if c.buffer != nil && c.buffer() != "" {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(input), ":")
+}
+
+// result ranks candidates for the typed word and converts them to
+// readline's format.
+func (c *SQLCompleter) result(line []rune, pos int, lastWord string, candidates []Candidate) ([][]rune, int) {
+	matches := Rank(lastWord, candidates, c.mode)
+	if c.mode == MatchFuzzy || !extendWord(lastWord, matches) {
+		return c.fuzzyResult(line, pos, lastWord, matches)
+	}
 
 	// Convert to readline format
+	wordLen := len([]rune(lastWord))
+	result := make([][]rune, len(matches))
+	for i, match := range matches {
+		result[i] = []rune(match)[wordLen:]
+	}
+
+	return result, wordLen
+}
+
+// extendWord reports whether every match starts with word exactly, so that
+// readline can append the rest. Otherwise, e.g. for a name that had to be
+// quoted, the word is replaced as in fuzzy mode.
+func extendWord(word string, matches []string) bool {
+	for _, match := range matches {
+		if !strings.HasPrefix(match, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyResult returns whole candidates, which readline inserts after the
+// typed word; OnChange then replaces the word with the inserted text.
+func (c *SQLCompleter) fuzzyResult(line []rune, pos int, word string, matches []string) ([][]rune, int) {
+	c.pending = nil
+	if len(matches) == 0 {
+		return nil, 0
+	}
+	c.pending = &replacement{
+		line:       append([]rune(nil), line...),
+		pos:        pos,
+		wordLen:    len([]rune(word)),
+		candidates: matches,
+	}
+
 	result := make([][]rune, len(matches))
 	for i, match := range matches {
-		suffix := match[len(lastWord):]
-		result[i] = []rune(suffix)
+		result[i] = []rune(match)
+	}
+
+	// readline inserts the common prefix of several candidates at once.
+	// Unless it extends the typed word, an empty last candidate prevents
+	// that and the candidates are listed instead.
+	if len(result) > 1 {
+		common := commonPrefix(matches)
+		if len(common) <= len(word) || !strings.HasPrefix(strings.ToLower(common), strings.ToLower(word)) {
+			result = append(result, []rune{})
+		}
+	}
+	return result, 0
+}
+
+// OnChange implements readline.Listener. When a fuzzy candidate has just
+// been inserted after the typed word, the word is replaced by it.
+// Candidates are inserted by Tab or Enter, never by a printable key.
+func (c *SQLCompleter) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	p := c.pending
+	if p == nil || key >= ' ' || string(line) == string(p.line) {
+		return nil, 0, false
+	}
+	c.pending = nil
+
+	head, tail := p.line[:p.pos], p.line[p.pos:]
+	if len(line) <= len(p.line) || string(line[:len(head)]) != string(head) ||
+		string(line[len(line)-len(tail):]) != string(tail) {
+		return nil, 0, false
+	}
+	inserted := line[len(head) : len(line)-len(tail)]
+	if !p.offers(string(inserted)) {
+		return nil, 0, false
+	}
+
+	newLine := make([]rune, 0, len(line))
+	newLine = append(newLine, head[:len(head)-p.wordLen]...)
+	newLine = append(newLine, inserted...)
+	newLine = append(newLine, tail...)
+	return newLine, len(head) - p.wordLen + len(inserted), true
+}
+
+// offers reports whether text is a candidate or the common prefix of
+// several, rather than something typed.
+func (p *replacement) offers(text string) bool {
+	for _, candidate := range p.candidates {
+		if strings.HasPrefix(candidate, text) {
+			return true
+		}
 	}
+	return false
+}
 
-	return result, len(lastWord)
+// commonPrefix returns the longest common prefix of items, compared
+// exactly as readline does.
+func commonPrefix(items []string) string {
+	prefix := items[0]
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
 }
 
 func (c *SQLCompleter) getTableCandidates(ctx context.Context) []string {
-	tables, err := c.cache.GetTables(ctx)
+	tables, err := c.cache.GetTables(ctx, "")
 	if err != nil {
 		return nil
 	}
 	return tables
 }
 
-func (c *SQLCompleter) getColumnCandidates(ctx context.Context, input string) []string {
-	// Get tables referenced in the query
-	tables := GetTableContext(input)
+func (c *SQLCompleter) getSchemaTableCandidates(ctx context.Context, schema string) []string {
+	tables, err := c.cache.GetTables(ctx, schema)
+	if err != nil {
+		return nil
+	}
+	return tables
+}
 
+// getQualifiedColumnCandidates returns the columns of the referenced table
+// whose alias or name is qualifier, as in "WHERE o.|".
+func (c *SQLCompleter) getQualifiedColumnCandidates(ctx context.Context, tables []TableRef, qualifier string) []string {
+	if table, ok := qualifiedTable(tables, qualifier); ok {
+		return c.tableColumns(ctx, table)
+	}
+	return nil
+}
+
+// qualifiedTable returns the referenced table whose alias or name is
+// qualifier.
+func qualifiedTable(tables []TableRef, qualifier string) (TableRef, bool) {
+	// An alias hides the name of its table
+	for _, table := range tables {
+		if table.Alias != "" && strings.EqualFold(table.Alias, qualifier) {
+			return table, true
+		}
+	}
+	for _, table := range tables {
+		if table.Matches(qualifier) {
+			return table, true
+		}
+	}
+	return TableRef{}, false
+}
+
+// getValueCandidates returns literals for the column of target: the
+// allowed values of an ENUM or SET column, or a sample of the values of
+// a column with a non-unique index.
+func (c *SQLCompleter) getValueCandidates(ctx context.Context, tables []TableRef, target ValueTarget) []string {
+	qualifier, name := db.SplitQualifiedName(target.Column)
+	if qualifier != "" {
+		table, ok := qualifiedTable(tables, qualifier)
+		if !ok {
+			return nil
+		}
+		tables = []TableRef{table}
+	}
+
+	for _, table := range tables {
+		// Columns of derived tables and CTEs have no type
+		if table.Columns != nil || table.Name == "" {
+			continue
+		}
+		cols, err := c.cache.GetColumns(ctx, table.Schema, table.Name)
+		if err != nil {
+			continue
+		}
+		for _, col := range cols {
+			if !strings.EqualFold(col.Name, name) {
+				continue
+			}
+			values := col.EnumValues()
+			// Primary and unique keys have a value per row; only
+			// non-unique indexes are worth sampling
+			if values == nil && col.Key == "MUL" {
+				values, _ = c.cache.GetColumnValues(ctx, table.Schema, table.Name, col.Name)
+			}
+			literals := make([]string, len(values))
+			for i, value := range values {
+				literals[i] = QuoteValue(target, value)
+			}
+			return literals
+		}
+	}
+	return nil
+}
+
+func (c *SQLCompleter) getColumnCandidates(ctx context.Context, tables []TableRef) []string {
 	if len(tables) > 0 {
 		// Get columns from specific tables
 		columnSet := make(map[string]struct{})
 		for _, table := range tables {
-			cols, err := c.cache.GetColumns(ctx, table)
-			if err != nil {
-				continue
-			}
-			for _, col := range cols {
-				columnSet[col.Name] = struct{}{}
+			for _, col := range c.tableColumns(ctx, table) {
+				columnSet[col] = struct{}{}
 			}
 		}
 
@@ -113,6 +388,43 @@ func (c *SQLCompleter) getColumnCandidates(ctx context.Context, input string) []
 	return columns
 }
 
+// getJoinCandidates returns join conditions for the table joined with
+// "JOIN t ON", derived from foreign keys or the <table>_id convention.
+func (c *SQLCompleter) getJoinCandidates(ctx context.Context, joined TableRef, tables []TableRef) []string {
+	var fks []db.ForeignKeyInfo
+	if joined.Name != "" && joined.Columns == nil {
+		fks, _ = c.cache.GetForeignKeys(ctx, joined.Schema, joined.Name)
+	}
+	return JoinPredicates(joined, tables, fks, func(t TableRef) []string {
+		return c.tableColumns(ctx, t)
+	})
+}
+
+// tableColumns returns the column names of a referenced table. Derived
+// tables and CTEs provide their own columns.
+func (c *SQLCompleter) tableColumns(ctx context.Context, table TableRef) []string {
+	if table.Columns != nil || table.Name == "" {
+		return table.Columns
+	}
+	cols, err := c.cache.GetColumns(ctx, table.Schema, table.Name)
+	if err != nil {
+		return nil
+	}
+	columns := make([]string, len(cols))
+	for i, col := range cols {
+		columns[i] = col.Name
+	}
+	return columns
+}
+
+func (c *SQLCompleter) getAllColumnCandidates(ctx context.Context) []string {
+	columns, err := c.cache.GetAllColumns(ctx)
+	if err != nil {
+		return nil
+	}
+	return columns
+}
+
 func (c *SQLCompleter) getDatabaseCandidates(ctx context.Context) []string {
 	dbs, err := c.cache.GetDatabases(ctx)
 	if err != nil {
@@ -121,6 +433,17 @@ func (c *SQLCompleter) getDatabaseCandidates(ctx context.Context) []string {
 	return dbs
 }
 
+// getFunctionCandidates returns built-in functions with their opening
+// parenthesis, e.g. "COUNT(".
+func (c *SQLCompleter) getFunctionCandidates() []string {
+	functions := c.dialect.Functions()
+	names := make([]string, len(functions))
+	for i, f := range functions {
+		names[i] = f.Name + "("
+	}
+	return names
+}
+
 func (c *SQLCompleter) getKeywordCandidates() []string {
 	return c.dialect.Keywords()
 }
@@ -147,3 +470,6 @@ func uniqueSorted(items []string) []string {
 
 // Ensure SQLCompleter implements readline.AutoCompleter
 var _ readline.AutoCompleter = (*SQLCompleter)(nil)
+
+// Ensure SQLCompleter implements readline.Listener
+var _ readline.Listener = (*SQLCompleter)(nil)
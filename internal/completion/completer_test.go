@@ -0,0 +1,72 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyReplacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		word     string
+		matches  []string
+		inserted string
+		expected string
+	}{
+		{
+			name:     "single candidate replaces the word",
+			line:     "SELECT * FROM ordit",
+			word:     "ordit",
+			matches:  []string{"order_items"},
+			inserted: "order_items",
+			expected: "SELECT * FROM order_items",
+		},
+		{
+			name:     "common prefix replaces the word",
+			line:     "SELECT * FROM ord",
+			word:     "ord",
+			matches:  []string{"order_items", "orders"},
+			inserted: "order",
+			expected: "SELECT * FROM order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &SQLCompleter{mode: MatchFuzzy}
+			line := []rune(tt.line)
+			c.fuzzyResult(line, len(line), tt.word, tt.matches)
+
+			after := string(line) + tt.inserted
+			newLine, pos, ok := c.OnChange([]rune(after), len([]rune(after)), '\t')
+			if !ok {
+				t.Fatal("OnChange did not replace the word")
+			}
+			if string(newLine) != tt.expected || pos != len([]rune(tt.expected)) {
+				t.Errorf("OnChange = %q at %d, want %q", string(newLine), pos, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFuzzyResultKeepsWord(t *testing.T) {
+	c := &SQLCompleter{mode: MatchFuzzy}
+	line := []rune("SELECT uid")
+
+	// "u" would shorten the typed word, so it must not be inserted at once
+	result, offset := c.fuzzyResult(line, len(line), "uid", []string{"user_id", "uuid"})
+	expected := [][]rune{[]rune("user_id"), []rune("uuid"), {}}
+	if !reflect.DeepEqual(result, expected) || offset != 0 {
+		t.Errorf("fuzzyResult = %q, %d, want %q, 0", result, offset, expected)
+	}
+
+	// Typing after the candidates are listed is not a completion, even
+	// when the typed character starts a candidate
+	for _, typed := range []rune{'x', 'u'} {
+		after := "SELECT uid" + string(typed)
+		if _, _, ok := c.OnChange([]rune(after), 11, typed); ok {
+			t.Errorf("OnChange replaced the typed character %q", typed)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseScopeTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []TableRef
+	}{
+		{
+			name:     "unqualified table",
+			input:    "SELECT * FROM users WHERE ",
+			expected: []TableRef{{Name: "users"}},
+		},
+		{
+			name:     "schema qualified table",
+			input:    "SELECT * FROM other_db.orders WHERE ",
+			expected: []TableRef{{Schema: "other_db", Name: "orders"}},
+		},
+		{
+			name:     "backquoted parts",
+			input:    "SELECT * FROM `my db`.`order items` WHERE ",
+			expected: []TableRef{{Schema: "my db", Name: "order items"}},
+		},
+		{
+			name:  "join across databases",
+			input: "SELECT * FROM users JOIN shop.orders ON ",
+			expected: []TableRef{
+				{Name: "users"},
+				{Schema: "shop", Name: "orders"},
+			},
+		},
+		{
+			name:  "aliases with and without AS",
+			input: "SELECT u. FROM users u JOIN orders AS o ON ",
+			expected: []TableRef{
+				{Name: "users", Alias: "u"},
+				{Name: "orders", Alias: "o"},
+			},
+		},
+		{
+			name:  "comma separated tables",
+			input: "SELECT * FROM users u, shop.orders o WHERE ",
+			expected: []TableRef{
+				{Name: "users", Alias: "u"},
+				{Schema: "shop", Name: "orders", Alias: "o"},
+			},
+		},
+		{
+			name:  "derived table",
+			input: "SELECT t. FROM (SELECT id, name AS n, COUNT(*) cnt FROM users) t",
+			expected: []TableRef{
+				{Alias: "t", Columns: []string{"id", "n", "cnt"}},
+			},
+		},
+		{
+			name:     "inside derived table",
+			input:    "SELECT * FROM (SELECT id FROM users WHERE ",
+			expected: []TableRef{{Name: "users"}},
+		},
+		{
+			name:  "cte",
+			input: "WITH recent AS (SELECT u.id, created_at FROM users u) SELECT r. FROM recent r",
+			expected: []TableRef{
+				{Name: "recent", Alias: "r", Columns: []string{"id", "created_at"}},
+			},
+		},
+		{
+			name:  "cte with column list",
+			input: "WITH RECURSIVE n (v) AS (SELECT 1) SELECT * FROM n",
+			expected: []TableRef{
+				{Name: "n", Columns: []string{"v"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseScope(tt.input, len(tt.input)).Tables
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseScope(%q).Tables = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetQualifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no qualifier",
+			input:    "SELECT * FROM ord",
+			expected: "",
+		},
+		{
+			name:     "after dot",
+			input:    "SELECT * FROM shop.",
+			expected: "shop",
+		},
+		{
+			name:     "partial table",
+			input:    "SELECT * FROM shop.ord",
+			expected: "shop",
+		},
+		{
+			name:     "backquoted schema",
+			input:    "SELECT * FROM `shop`.",
+			expected: "shop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GetQualifier(tt.input)
+			if result != tt.expected {
+				t.Errorf("GetQualifier(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseScopeContextQualified(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected CompletionContext
+	}{
+		{
+			name:     "table after schema dot",
+			input:    "SELECT * FROM shop.",
+			expected: ContextTable,
+		},
+		{
+			name:     "partial qualified table",
+			input:    "SELECT * FROM shop.ord",
+			expected: ContextTable,
+		},
+		{
+			name:     "column after qualified table",
+			input:    "SELECT * FROM shop.orders WHERE ",
+			expected: ContextColumn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseScope(tt.input, len(tt.input)).Context()
+			if result != tt.expected {
+				t.Errorf("ParseScope(%q).Context() = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// SchemaChange describes how a statement affects cached metadata.
+type SchemaChange struct {
+	// Tables lists the tables whose definition changed.
+	Tables []TableRef
+	// TableList is set when tables were created, dropped or renamed.
+	TableList bool
+	// Databases is set when databases were created or dropped.
+	Databases bool
+	// Use is the database selected by a USE statement.
+	Use string
+	// Objects is set when views, routines or triggers were created,
+	// altered or dropped.
+	Objects bool
+}
+
+// IsEmpty reports whether the statement leaves metadata unchanged.
+func (s SchemaChange) IsEmpty() bool {
+	return len(s.Tables) == 0 && !s.TableList && !s.Databases && s.Use == "" && !s.Objects
+}
+
+// DetectSchemaChange inspects a statement for DDL and USE.
+func DetectSchemaChange(query string) SchemaChange {
+	tokens := GetNonWhitespaceTokens(Tokenize(query))
+	var change SchemaChange
+	if len(tokens) == 0 {
+		return change
+	}
+
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = strings.ToUpper(t.Value)
+	}
+
+	switch words[0] {
+	case "CREATE", "ALTER", "DROP":
+		change.Objects = namesObject(words)
+	}
+
+	switch words[0] {
+	case "USE":
+		if len(tokens) > 1 && tokens[1].Type == TokenWord {
+			change.Use = strings.Trim(tokens[1].Value, "`")
+		}
+	case "CREATE", "DROP":
+		i := indexOfObjectKeyword(words)
+		if i < 0 {
+			return change
+		}
+		switch words[i] {
+		case "TABLE", "VIEW":
+			change.TableList = true
+			change.Tables = tableList(tokens, words, skipIfExists(words, i+1))
+		case "INDEX":
+			// CREATE INDEX idx ON t / DROP INDEX idx ON t
+			for j := i + 1; j+1 < len(words); j++ {
+				if words[j] == "ON" {
+					change.Tables = firstTable(tableList(tokens, words, j+1))
+					break
+				}
+			}
+		case "DATABASE", "SCHEMA":
+			change.Databases = true
+		}
+	case "ALTER":
+		i := indexOfObjectKeyword(words)
+		if i < 0 {
+			return change
+		}
+		switch words[i] {
+		case "TABLE", "VIEW":
+			change.Tables = firstTable(tableList(tokens, words, i+1))
+			// ALTER TABLE a RENAME [TO|AS] b
+			for j := i + 2; j < len(words); j++ {
+				if words[j] != "RENAME" || j+1 >= len(words) || words[j+1] == "COLUMN" ||
+					words[j+1] == "INDEX" || words[j+1] == "KEY" {
+					continue
+				}
+				k := j + 1
+				if words[k] == "TO" || words[k] == "AS" {
+					k++
+				}
+				change.TableList = true
+				change.Tables = append(change.Tables, tableList(tokens, words, k)...)
+			}
+		case "DATABASE", "SCHEMA":
+			change.Databases = true
+		}
+	case "RENAME":
+		// RENAME TABLE a TO b, c TO d
+		if len(words) > 1 && words[1] == "TABLE" {
+			change.TableList = true
+			for i := 2; i < len(tokens); i++ {
+				if tokens[i].Type == TokenWord && words[i] != "TO" {
+					change.Tables = append(change.Tables, tableRef(tokens[i]))
+				}
+			}
+		}
+	}
+
+	return change
+}
+
+// indexOfObjectKeyword finds the object kind of a CREATE, ALTER or DROP
+// statement, skipping modifiers such as TEMPORARY, OR REPLACE or DEFINER.
+func indexOfObjectKeyword(words []string) int {
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "TABLE", "VIEW", "INDEX", "DATABASE", "SCHEMA":
+			return i
+		case "PROCEDURE", "FUNCTION", "TRIGGER", "EVENT", "USER", "ROLE":
+			return -1
+		}
+	}
+	return -1
+}
+
+// namesObject reports whether a CREATE, ALTER or DROP statement defines a
+// view, routine or trigger.
+func namesObject(words []string) bool {
+	for _, w := range words[1:] {
+		switch w {
+		case "VIEW", "PROCEDURE", "FUNCTION", "TRIGGER":
+			return true
+		case "TABLE", "INDEX", "DATABASE", "SCHEMA", "EVENT", "USER", "ROLE":
+			return false
+		}
+	}
+	return false
+}
+
+func skipIfExists(words []string, i int) int {
+	for i < len(words) && (words[i] == "IF" || words[i] == "NOT" || words[i] == "EXISTS") {
+		i++
+	}
+	return i
+}
+
+// tableList reads a comma-separated list of table names starting at i.
+func tableList(tokens []Token, words []string, i int) []TableRef {
+	var tables []TableRef
+	for i < len(tokens) && tokens[i].Type == TokenWord {
+		tables = append(tables, tableRef(tokens[i]))
+		if i+1 >= len(tokens) || words[i+1] != "," {
+			break
+		}
+		i += 2
+	}
+	return tables
+}
+
+func firstTable(tables []TableRef) []TableRef {
+	if len(tables) > 1 {
+		return tables[:1]
+	}
+	return tables
+}
+
+func tableRef(t Token) TableRef {
+	schema, name := db.SplitQualifiedName(t.Value)
+	return TableRef{Schema: schema, Name: name}
+}
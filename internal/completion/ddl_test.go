@@ -0,0 +1,103 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectSchemaChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected SchemaChange
+	}{
+		{
+			name:     "select",
+			query:    "SELECT * FROM users",
+			expected: SchemaChange{},
+		},
+		{
+			name:     "create table",
+			query:    "CREATE TABLE IF NOT EXISTS users (id INT, INDEX idx (id))",
+			expected: SchemaChange{Tables: []TableRef{{Name: "users"}}, TableList: true},
+		},
+		{
+			name:     "create temporary table",
+			query:    "create temporary table tmp (id int)",
+			expected: SchemaChange{Tables: []TableRef{{Name: "tmp"}}, TableList: true},
+		},
+		{
+			name:  "drop several tables",
+			query: "DROP TABLE IF EXISTS a, shop.b",
+			expected: SchemaChange{
+				Tables:    []TableRef{{Name: "a"}, {Schema: "shop", Name: "b"}},
+				TableList: true,
+			},
+		},
+		{
+			name:     "alter table add column",
+			query:    "ALTER TABLE `users` ADD COLUMN age INT",
+			expected: SchemaChange{Tables: []TableRef{{Name: "users"}}},
+		},
+		{
+			name:     "alter table rename column",
+			query:    "ALTER TABLE users RENAME COLUMN a TO b",
+			expected: SchemaChange{Tables: []TableRef{{Name: "users"}}},
+		},
+		{
+			name:  "alter table rename to",
+			query: "ALTER TABLE users RENAME TO members",
+			expected: SchemaChange{
+				Tables:    []TableRef{{Name: "users"}, {Name: "members"}},
+				TableList: true,
+			},
+		},
+		{
+			name:  "rename table",
+			query: "RENAME TABLE a TO b, c TO d",
+			expected: SchemaChange{
+				Tables:    []TableRef{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+				TableList: true,
+			},
+		},
+		{
+			name:     "create unique index",
+			query:    "CREATE UNIQUE INDEX idx_email ON users (email)",
+			expected: SchemaChange{Tables: []TableRef{{Name: "users"}}},
+		},
+		{
+			name:     "create or replace view",
+			query:    "CREATE OR REPLACE VIEW active_users AS SELECT * FROM users",
+			expected: SchemaChange{Tables: []TableRef{{Name: "active_users"}}, TableList: true, Objects: true},
+		},
+		{
+			name:     "create trigger",
+			query:    "CREATE TRIGGER trg BEFORE INSERT ON users FOR EACH ROW SET NEW.a = 1",
+			expected: SchemaChange{Objects: true},
+		},
+		{
+			name:     "drop procedure",
+			query:    "DROP PROCEDURE IF EXISTS refresh_totals",
+			expected: SchemaChange{Objects: true},
+		},
+		{
+			name:     "drop database",
+			query:    "DROP DATABASE scratch",
+			expected: SchemaChange{Databases: true},
+		},
+		{
+			name:     "use",
+			query:    "USE `shop`",
+			expected: SchemaChange{Use: "shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectSchemaChange(tt.query)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("DetectSchemaChange(%q) = %+v, want %+v", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// JoinPredicates returns join conditions between the joined table and the
+// other tables in scope, such as "o.user_id = u.id". Foreign keys of the
+// joined table are used when there are any between the tables; otherwise
+// the <table>_id naming convention is tried. columns returns the column
+// names of a table.
+func JoinPredicates(joined TableRef, others []TableRef, fks []db.ForeignKeyInfo, columns func(TableRef) []string) []string {
+	var predicates []string
+	seen := make(map[string]struct{})
+	add := func(p string) {
+		if _, ok := seen[p]; !ok {
+			seen[p] = struct{}{}
+			predicates = append(predicates, p)
+		}
+	}
+
+	for _, other := range others {
+		if sameRef(other, joined) {
+			continue
+		}
+		for _, fk := range fks {
+			// A self-referencing key applies in both directions
+			if strings.EqualFold(fk.Table, joined.Name) && strings.EqualFold(fk.RefTable, other.Name) {
+				add(predicate(joined, fk.Columns, other, fk.RefColumns))
+			}
+			if strings.EqualFold(fk.RefTable, joined.Name) && strings.EqualFold(fk.Table, other.Name) {
+				add(predicate(joined, fk.RefColumns, other, fk.Columns))
+			}
+		}
+	}
+	if len(predicates) > 0 {
+		return predicates
+	}
+
+	// No foreign keys: orders.user_id = users.id
+	joinedCols := columns(joined)
+	for _, other := range others {
+		if sameRef(other, joined) {
+			continue
+		}
+		otherCols := columns(other)
+		for _, col := range conventionColumns(joinedCols, other.Name) {
+			if hasColumn(otherCols, "id") {
+				add(predicate(joined, []string{col}, other, []string{"id"}))
+			}
+		}
+		for _, col := range conventionColumns(otherCols, joined.Name) {
+			if hasColumn(joinedCols, "id") {
+				add(predicate(joined, []string{"id"}, other, []string{col}))
+			}
+		}
+	}
+	return predicates
+}
+
+// predicate builds "a.x = b.y AND ..." for column pairs.
+func predicate(left TableRef, leftCols []string, right TableRef, rightCols []string) string {
+	var parts []string
+	for i := 0; i < len(leftCols) && i < len(rightCols); i++ {
+		parts = append(parts, refName(left)+"."+leftCols[i]+" = "+refName(right)+"."+rightCols[i])
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// refName is the name a table is referred to by in the query.
+func refName(t TableRef) string {
+	if t.Alias != "" {
+		return t.Alias
+	}
+	return t.Name
+}
+
+func sameRef(a, b TableRef) bool {
+	return strings.EqualFold(a.Schema, b.Schema) && strings.EqualFold(a.Name, b.Name) &&
+		strings.EqualFold(a.Alias, b.Alias)
+}
+
+// conventionColumns returns the columns named after table, e.g. user_id
+// for users.
+func conventionColumns(columns []string, table string) []string {
+	if table == "" {
+		return nil
+	}
+	names := []string{strings.ToLower(table) + "_id", singular(strings.ToLower(table)) + "_id"}
+	var found []string
+	for _, name := range names {
+		for _, col := range columns {
+			if strings.EqualFold(col, name) && !hasColumn(found, col) {
+				found = append(found, col)
+			}
+		}
+	}
+	return found
+}
+
+// singular strips a plural suffix from a table name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"), strings.HasSuffix(name, "xes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, col := range columns {
+		if strings.EqualFold(col, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+func TestJoinPredicates(t *testing.T) {
+	columns := map[string][]string{
+		"users":      {"id", "name"},
+		"orders":     {"id", "user_id", "total"},
+		"categories": {"id", "name"},
+		"products":   {"id", "category_id"},
+		"employees":  {"id", "manager_id"},
+	}
+	columnsOf := func(t TableRef) []string { return columns[t.Name] }
+
+	tests := []struct {
+		name     string
+		joined   TableRef
+		others   []TableRef
+		fks      []db.ForeignKeyInfo
+		expected []string
+	}{
+		{
+			name:   "foreign key of the joined table",
+			joined: TableRef{Name: "orders", Alias: "o"},
+			others: []TableRef{{Name: "users", Alias: "u"}, {Name: "orders", Alias: "o"}},
+			fks: []db.ForeignKeyInfo{
+				{Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+			},
+			expected: []string{"o.user_id = u.id"},
+		},
+		{
+			name:   "foreign key referencing the joined table",
+			joined: TableRef{Name: "users"},
+			others: []TableRef{{Name: "orders"}},
+			fks: []db.ForeignKeyInfo{
+				{Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+			},
+			expected: []string{"users.id = orders.user_id"},
+		},
+		{
+			name:   "composite foreign key",
+			joined: TableRef{Name: "lines"},
+			others: []TableRef{{Name: "shipments"}},
+			fks: []db.ForeignKeyInfo{
+				{Table: "lines", Columns: []string{"order_id", "line_no"}, RefTable: "shipments", RefColumns: []string{"order_id", "line_no"}},
+			},
+			expected: []string{"lines.order_id = shipments.order_id AND lines.line_no = shipments.line_no"},
+		},
+		{
+			name:     "naming convention without foreign keys",
+			joined:   TableRef{Name: "orders", Alias: "o"},
+			others:   []TableRef{{Name: "users", Alias: "u"}},
+			expected: []string{"o.user_id = u.id"},
+		},
+		{
+			name:     "naming convention from the other side",
+			joined:   TableRef{Name: "categories", Alias: "c"},
+			others:   []TableRef{{Name: "products", Alias: "p"}},
+			expected: []string{"c.id = p.category_id"},
+		},
+		{
+			name:     "self join by convention is not guessed",
+			joined:   TableRef{Name: "employees", Alias: "m"},
+			others:   []TableRef{{Name: "employees", Alias: "e"}},
+			expected: nil,
+		},
+		{
+			name:   "self join by foreign key",
+			joined: TableRef{Name: "employees", Alias: "m"},
+			others: []TableRef{{Name: "employees", Alias: "e"}},
+			fks: []db.ForeignKeyInfo{
+				{Table: "employees", Columns: []string{"manager_id"}, RefTable: "employees", RefColumns: []string{"id"}},
+			},
+			expected: []string{"m.manager_id = e.id", "m.id = e.manager_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := JoinPredicates(tt.joined, tt.others, tt.fks, columnsOf)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("JoinPredicates() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
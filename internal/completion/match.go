@@ -0,0 +1,213 @@
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatchMode selects how the typed word is matched against candidates.
+type MatchMode int
+
+const (
+	// MatchPrefix keeps candidates starting with the typed word, sorted
+	// alphabetically.
+	MatchPrefix MatchMode = iota
+	// MatchFuzzy keeps candidates containing the typed characters in order,
+	// e.g. "ordit" for order_items, ranked by match quality and kind.
+	MatchFuzzy
+)
+
+// ParseMatchMode parses a match mode name.
+func ParseMatchMode(s string) (MatchMode, error) {
+	switch strings.ToLower(s) {
+	case "prefix":
+		return MatchPrefix, nil
+	case "fuzzy":
+		return MatchFuzzy, nil
+	default:
+		return MatchPrefix, fmt.Errorf("unknown completion mode: %s (use fuzzy or prefix)", s)
+	}
+}
+
+// String returns the name of the mode.
+func (m MatchMode) String() string {
+	if m == MatchFuzzy {
+		return "fuzzy"
+	}
+	return "prefix"
+}
+
+// CandidateKind orders candidates of equal match quality; lower kinds are
+// listed first.
+type CandidateKind int
+
+const (
+	KindValue CandidateKind = iota
+	KindJoin
+	KindScopeColumn
+	KindColumn
+	KindFunction
+	KindTable
+	KindDatabase
+	KindKeyword
+)
+
+// Candidate is a completion candidate.
+type Candidate struct {
+	Text string
+	Kind CandidateKind
+}
+
+// candidatesOf wraps names as candidates of one kind.
+func candidatesOf(kind CandidateKind, names []string) []Candidate {
+	candidates := make([]Candidate, len(names))
+	for i, name := range names {
+		candidates[i] = Candidate{Text: name, Kind: kind}
+	}
+	return candidates
+}
+
+// Rank returns the candidates matching word. Prefix mode sorts them
+// alphabetically; fuzzy mode puts prefix matches first, then orders by
+// kind and match score.
+func Rank(word string, candidates []Candidate, mode MatchMode) []string {
+	wordLower := strings.ToLower(word)
+	// A quoted name matches an unquoted word
+	key := func(text string) string {
+		lower := strings.ToLower(text)
+		if !strings.HasPrefix(word, "`") {
+			lower = strings.TrimPrefix(lower, "`")
+		}
+		return lower
+	}
+
+	if mode == MatchPrefix {
+		var matches []string
+		for _, candidate := range candidates {
+			if strings.HasPrefix(key(candidate.Text), wordLower) {
+				matches = append(matches, candidate.Text)
+			}
+		}
+		return uniqueSorted(matches)
+	}
+
+	type ranked struct {
+		Candidate
+		prefix bool
+		score  int
+	}
+	best := make(map[string]int)
+	var matches []ranked
+	for _, candidate := range candidates {
+		lower := key(candidate.Text)
+		score, ok := FuzzyScore(wordLower, lower)
+		if !ok {
+			continue
+		}
+		m := ranked{Candidate: candidate, prefix: strings.HasPrefix(lower, wordLower), score: score}
+		// Keep the best kind of a name offered more than once
+		if i, seen := best[lower]; seen {
+			if m.Kind < matches[i].Kind {
+				matches[i] = m
+			}
+			continue
+		}
+		best[lower] = len(matches)
+		matches = append(matches, m)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.prefix != b.prefix:
+			return a.prefix
+		case a.Kind != b.Kind:
+			return a.Kind < b.Kind
+		case a.score != b.score:
+			return a.score > b.score
+		case len(a.Text) != len(b.Text):
+			return len(a.Text) < len(b.Text)
+		}
+		return a.Text < b.Text
+	})
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.Text
+	}
+	return result
+}
+
+// Scores of FuzzyScore.
+const (
+	scoreMatch       = 1
+	scoreBoundary    = 8
+	scoreConsecutive = 8
+	penaltyGap       = 2
+)
+
+// FuzzyScore reports whether the characters of pattern appear in text in
+// order and scores the best such match. Matches at word starts (after _,
+// . or a space) and runs of consecutive characters score higher; skipped
+// characters between matches lower the score. Both arguments are expected
+// in the same case.
+func FuzzyScore(pattern, text string) (int, bool) {
+	p, t := []rune(pattern), []rune(text)
+	if len(p) == 0 {
+		return 0, true
+	}
+	if len(p) > len(t) {
+		return 0, false
+	}
+
+	const none = -1 << 30
+	// prev[i] is the best score with the previous pattern character at t[i]
+	prev := make([]int, len(t))
+	cur := make([]int, len(t))
+	for i := range t {
+		prev[i] = none
+		if t[i] == p[0] {
+			prev[i] = scoreMatch + boundaryBonus(t, i)
+		}
+	}
+
+	for j := 1; j < len(p); j++ {
+		for i := range t {
+			cur[i] = none
+			if t[i] != p[j] {
+				continue
+			}
+			for k := j - 1; k < i; k++ {
+				if prev[k] == none {
+					continue
+				}
+				s := prev[k] + scoreMatch + boundaryBonus(t, i)
+				if k == i-1 {
+					s += scoreConsecutive
+				} else {
+					s -= penaltyGap * (i - k - 1)
+				}
+				if s > cur[i] {
+					cur[i] = s
+				}
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	best := none
+	for _, s := range prev {
+		if s > best {
+			best = s
+		}
+	}
+	return best, best != none
+}
+
+func boundaryBonus(t []rune, i int) int {
+	if i == 0 || strings.ContainsRune("_. `(", t[i-1]) {
+		return scoreBoundary
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		match   bool
+	}{
+		{"ordit", "order_items", true},
+		{"uid", "user_id", true},
+		{"", "users", true},
+		{"usr", "users", true},
+		{"sru", "users", false},
+		{"orders_x", "orders", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := FuzzyScore(tt.pattern, tt.text); ok != tt.match {
+			t.Errorf("FuzzyScore(%q, %q) matched = %v, want %v", tt.pattern, tt.text, ok, tt.match)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	// Each pair lists a better match first
+	tests := []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"uid", "user_id", "build"},
+		{"ordit", "order_items", "ordinal_items"},
+		{"ord", "ord_total", "o_r_d"},
+	}
+
+	for _, tt := range tests {
+		b, _ := FuzzyScore(tt.pattern, tt.better)
+		w, _ := FuzzyScore(tt.pattern, tt.worse)
+		if b <= w {
+			t.Errorf("FuzzyScore(%q): %q = %d, want above %q = %d", tt.pattern, tt.better, b, tt.worse, w)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []Candidate{
+		{Text: "ORDER", Kind: KindKeyword},
+		{Text: "order_items", Kind: KindTable},
+		{Text: "order_id", Kind: KindColumn},
+		{Text: "ordered_at", Kind: KindScopeColumn},
+		{Text: "user_id", Kind: KindScopeColumn},
+		{Text: "user_id", Kind: KindColumn},
+		{Text: "uuid", Kind: KindColumn},
+	}
+
+	tests := []struct {
+		name     string
+		word     string
+		mode     MatchMode
+		expected []string
+	}{
+		{
+			name:     "prefix mode sorts alphabetically",
+			word:     "ord",
+			mode:     MatchPrefix,
+			expected: []string{"ORDER", "order_id", "order_items", "ordered_at"},
+		},
+		{
+			name:     "prefix mode ignores subsequences",
+			word:     "ordit",
+			mode:     MatchPrefix,
+			expected: nil,
+		},
+		{
+			name:     "fuzzy ranks scope columns above others and keywords last",
+			word:     "ord",
+			mode:     MatchFuzzy,
+			expected: []string{"ordered_at", "order_id", "order_items", "ORDER"},
+		},
+		{
+			name:     "fuzzy finds subsequences",
+			word:     "ordit",
+			mode:     MatchFuzzy,
+			expected: []string{"order_items"},
+		},
+		{
+			name:     "fuzzy keeps the best kind of duplicates",
+			word:     "uid",
+			mode:     MatchFuzzy,
+			expected: []string{"user_id", "uuid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Rank(tt.word, candidates, tt.mode)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Rank(%q) = %q, want %q", tt.word, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for _, name := range []string{"fuzzy", "prefix", "FUZZY"} {
+		mode, err := ParseMatchMode(name)
+		if err != nil {
+			t.Fatalf("ParseMatchMode(%q) error: %v", name, err)
+		}
+		if mode.String() != map[string]string{"fuzzy": "fuzzy", "prefix": "prefix", "FUZZY": "fuzzy"}[name] {
+			t.Errorf("ParseMatchMode(%q) = %s", name, mode)
+		}
+	}
+	if _, err := ParseMatchMode("exact"); err == nil {
+		t.Error("ParseMatchMode(\"exact\") expected error")
+	}
+}
@@ -0,0 +1,218 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// TableRef is a table referenced in a query. Schema is empty when the
+// reference is not qualified. Derived tables and CTEs carry the column
+// names of their select list in Columns; derived tables have no Name.
+type TableRef struct {
+	Schema  string
+	Name    string
+	Alias   string
+	Columns []string
+}
+
+// Matches reports whether qualifier, as in "qualifier.column", refers to
+// this table through its alias or its name.
+func (t TableRef) Matches(qualifier string) bool {
+	if t.Alias != "" && strings.EqualFold(t.Alias, qualifier) {
+		return true
+	}
+	return t.Name != "" && strings.EqualFold(t.Name, qualifier)
+}
+
+// clauseKeywords end a table reference; they are never taken as a table
+// name or alias.
+var clauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "JOIN": true, "ON": true,
+	"USING": true, "LEFT": true, "RIGHT": true, "INNER": true, "OUTER": true,
+	"CROSS": true, "NATURAL": true, "STRAIGHT_JOIN": true, "SET": true,
+	"GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true, "UNION": true,
+	"VALUES": true, "VALUE": true, "WINDOW": true, "FOR": true, "LOCK": true,
+	"INTO": true, "FORCE": true, "USE": true, "IGNORE": true, "PARTITION": true,
+	"AS": true, "AND": true, "OR": true, "NOT": true, "IS": true, "IN": true,
+	"ASC": true, "DESC": true, "WITH": true, "EXCEPT": true, "INTERSECT": true,
+}
+
+// appendTableRefs appends the table reference at i, and the ones that
+// follow it separated by commas when list is set, as in FROM a x, b y.
+func appendTableRefs(tables []TableRef, tokens []Token, i int, list bool, ctes map[string][]string) []TableRef {
+	for i < len(tokens) {
+		ref, next, ok := parseTableRef(tokens, i)
+		if !ok {
+			break
+		}
+		if cols, isCTE := ctes[strings.ToLower(ref.Name)]; isCTE && ref.Schema == "" {
+			ref.Columns = cols
+		}
+		tables = append(tables, ref)
+		if !list || next >= len(tokens) || tokens[next].Value != "," {
+			break
+		}
+		i = next + 1
+	}
+	return tables
+}
+
+// parseTableRef reads a table or derived table and its optional alias
+// starting at i. It returns the position after the reference.
+func parseTableRef(tokens []Token, i int) (TableRef, int, bool) {
+	var ref TableRef
+	switch {
+	case tokens[i].Value == "(":
+		end := matchingParen(tokens, i)
+		ref.Columns = selectListColumns(tokens[i+1 : end])
+		i = end + 1
+	case isName(tokens[i]):
+		ref.Schema, ref.Name = db.SplitQualifiedName(tokens[i].Value)
+		if ref.Name == "" {
+			return ref, i, false
+		}
+		i++
+	default:
+		return ref, i, false
+	}
+
+	if i < len(tokens) && strings.EqualFold(tokens[i].Value, "AS") {
+		i++
+	}
+	if i < len(tokens) && isName(tokens[i]) && !strings.Contains(tokens[i].Value, ".") {
+		ref.Alias = strings.Trim(tokens[i].Value, "`")
+		i++
+	}
+	return ref, i, ref.Name != "" || ref.Alias != ""
+}
+
+// parseCTEs returns the column names of each CTE of a WITH clause, keyed
+// by lower-case name.
+func parseCTEs(tokens []Token) map[string][]string {
+	ctes := make(map[string][]string)
+	if len(tokens) == 0 || !strings.EqualFold(tokens[0].Value, "WITH") {
+		return ctes
+	}
+
+	i := 1
+	if i < len(tokens) && strings.EqualFold(tokens[i].Value, "RECURSIVE") {
+		i++
+	}
+	for i < len(tokens) && isName(tokens[i]) {
+		name := strings.ToLower(strings.Trim(tokens[i].Value, "`"))
+		i++
+
+		// WITH x (a, b) AS (...) names the columns explicitly
+		var columns []string
+		if i < len(tokens) && tokens[i].Value == "(" {
+			end := matchingParen(tokens, i)
+			for _, t := range tokens[i+1 : end] {
+				if t.Type == TokenWord {
+					columns = append(columns, strings.Trim(t.Value, "`"))
+				}
+			}
+			i = end + 1
+		}
+		if i >= len(tokens) || !strings.EqualFold(tokens[i].Value, "AS") {
+			break
+		}
+		i++
+		if i >= len(tokens) || tokens[i].Value != "(" {
+			break
+		}
+		end := matchingParen(tokens, i)
+		if columns == nil {
+			columns = selectListColumns(tokens[i+1 : end])
+		}
+		ctes[name] = columns
+		i = end + 1
+
+		if i >= len(tokens) || tokens[i].Value != "," {
+			break
+		}
+		i++
+	}
+	return ctes
+}
+
+// selectListColumns returns the output column names of a SELECT statement.
+// Expressions without an alias and * are skipped.
+func selectListColumns(tokens []Token) []string {
+	if len(tokens) == 0 || !strings.EqualFold(tokens[0].Value, "SELECT") {
+		return nil
+	}
+
+	var columns []string
+	var item []Token
+	flush := func() {
+		if name := outputName(item); name != "" {
+			columns = append(columns, name)
+		}
+		item = item[:0]
+	}
+
+	depth := 0
+	for _, t := range tokens[1:] {
+		switch {
+		case t.Value == "(":
+			depth++
+		case t.Value == ")":
+			depth--
+		case depth == 0 && t.Value == ",":
+			flush()
+			continue
+		case depth == 0 && strings.EqualFold(t.Value, "FROM"):
+			flush()
+			return columns
+		}
+		item = append(item, t)
+	}
+	flush()
+	return columns
+}
+
+// outputName returns the name a select list item is known by.
+func outputName(item []Token) string {
+	if len(item) > 0 && (strings.EqualFold(item[0].Value, "DISTINCT") || strings.EqualFold(item[0].Value, "ALL")) {
+		item = item[1:]
+	}
+	if len(item) == 0 {
+		return ""
+	}
+
+	last := item[len(item)-1]
+	if !isName(last) {
+		return ""
+	}
+	// A single column, or an expression followed by an alias
+	if len(item) == 1 || isName(item[len(item)-2]) || strings.EqualFold(item[len(item)-2].Value, "AS") ||
+		item[len(item)-2].Value == ")" {
+		_, name := db.SplitQualifiedName(last.Value)
+		return name
+	}
+	return ""
+}
+
+// isName reports whether a token can be a table, alias or column name.
+func isName(t Token) bool {
+	return t.Type == TokenWord && !clauseKeywords[strings.ToUpper(t.Value)]
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// i, or len(tokens) when it is not closed yet.
+func matchingParen(tokens []Token, i int) int {
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		switch tokens[j].Value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(tokens)
+}
@@ -0,0 +1,290 @@
+package completion
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Clause is the part of a statement the cursor is in.
+type Clause int
+
+const (
+	ClauseNone Clause = iota
+	ClauseSelect
+	ClauseFrom
+	ClauseJoinOn
+	ClauseWhere
+	ClauseGroupBy
+	ClauseOrderBy
+	ClauseSet
+	ClauseInsertColumns
+	ClauseValues
+	ClauseLimit
+	ClauseUse
+)
+
+// Scope describes where the cursor is in a statement.
+type Scope struct {
+	// Statement is the upper-case leading keyword of the statement the
+	// cursor is in, e.g. SELECT for a subquery inside an UPDATE.
+	Statement string
+	// Clause is the clause the cursor is in.
+	Clause Clause
+	// Tables lists the tables visible at the cursor, those of the
+	// innermost query first. Tables named after the cursor are included.
+	Tables []TableRef
+	// Joined is the table of the JOIN whose ON condition starts at the
+	// cursor, as in "JOIN orders o ON |". It is nil elsewhere.
+	Joined *TableRef
+
+	expectTable bool
+	prevWord    string
+}
+
+// Context maps the scope to the kind of candidates to offer.
+func (s Scope) Context() CompletionContext {
+	switch {
+	case s.Clause == ClauseUse:
+		return ContextDatabase
+	case s.expectTable:
+		return ContextTable
+	}
+
+	switch s.Clause {
+	case ClauseSelect, ClauseJoinOn, ClauseWhere, ClauseGroupBy, ClauseOrderBy,
+		ClauseSet, ClauseInsertColumns:
+		return ContextColumn
+	}
+	if columnContextKeywords[s.prevWord] {
+		return ContextColumn
+	}
+	return ContextKeywordOrTable
+}
+
+// level is a parenthesised part of a statement. Query levels are the
+// statement itself and its subqueries and CTE bodies; other levels, such
+// as function arguments or an INSERT column list, belong to the enclosing
+// query.
+type level struct {
+	start     int
+	query     bool
+	statement string
+	clause    Clause
+	// joined is the token index of the table of the last JOIN.
+	joined int
+}
+
+// ParseScope parses statement up to cursor, a rune offset, and returns the
+// clause and tables in scope there. The text after the cursor is only used
+// to find the tables of the queries the cursor is in.
+func ParseScope(statement string, cursor int) Scope {
+	runes := []rune(statement)
+	if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	prefix := string(runes[:cursor])
+	before := GetNonWhitespaceTokens(Tokenize(prefix))
+	all := GetNonWhitespaceTokens(Tokenize(statement))
+
+	// The word being typed is not a keyword yet
+	typing := len(before) > 0 && before[len(before)-1].Type == TokenWord &&
+		cursor > 0 && !unicode.IsSpace(runes[cursor-1])
+	done := before
+	if typing {
+		done = before[:len(before)-1]
+	}
+
+	p := newScopeParser(all)
+	for i, t := range done {
+		p.advance(i, t)
+	}
+	return p.scope(done)
+}
+
+// scopeParser follows the levels of a statement token by token, so that
+// the scope at every token can be found in a single pass.
+type scopeParser struct {
+	// all holds the tokens of the whole statement.
+	all    []Token
+	ctes   map[string][]string
+	levels []level
+	// tables caches the tables of query levels by their start.
+	tables map[int][]TableRef
+}
+
+func newScopeParser(all []Token) *scopeParser {
+	return &scopeParser{
+		all:    all,
+		ctes:   parseCTEs(all),
+		levels: []level{{start: -1, query: true}},
+		tables: make(map[int][]TableRef),
+	}
+}
+
+// advance moves past t, the token at index i.
+func (p *scopeParser) advance(i int, t Token) {
+	cur := &p.levels[len(p.levels)-1]
+	upper := strings.ToUpper(t.Value)
+
+	switch {
+	case t.Value == "(":
+		next := level{start: i, clause: cur.clause, statement: cur.statement}
+		if cur.clause == ClauseFrom && cur.statement == "INSERT" {
+			next.clause = ClauseInsertColumns
+		}
+		p.levels = append(p.levels, next)
+		return
+	case t.Value == ")":
+		if len(p.levels) > 1 {
+			p.levels = p.levels[:len(p.levels)-1]
+		}
+		return
+	case t.Value == ";":
+		p.levels = []level{{start: -1, query: true}}
+		return
+	case t.Type != TokenWord:
+		return
+	}
+
+	first := i == 0 || i == cur.start+1
+	if first && (upper == "SELECT" || upper == "WITH") && cur.start >= 0 {
+		// A subquery or CTE body
+		cur.query = true
+		cur.statement = ""
+	}
+	if cur.statement == "" && isStatementKeyword(upper) {
+		cur.statement = upper
+	}
+
+	switch upper {
+	case "SELECT":
+		cur.clause = ClauseSelect
+		if cur.statement == "WITH" {
+			cur.statement = "SELECT"
+		}
+	case "FROM", "INTO", "TABLE":
+		cur.clause = ClauseFrom
+	case "JOIN", "STRAIGHT_JOIN":
+		cur.clause = ClauseFrom
+		cur.joined = i + 1
+	case "UPDATE":
+		if cur.statement == "INSERT" {
+			// INSERT ... ON DUPLICATE KEY UPDATE
+			cur.clause = ClauseSet
+		} else {
+			cur.clause = ClauseFrom
+			if cur.statement == "WITH" {
+				cur.statement = "UPDATE"
+			}
+		}
+	case "DELETE", "INSERT", "REPLACE":
+		if cur.statement == "WITH" {
+			cur.statement = upper
+		}
+	case "DESC", "DESCRIBE", "TRUNCATE", "DROP":
+		if first {
+			cur.clause = ClauseFrom
+		}
+	case "ON":
+		if cur.clause == ClauseFrom {
+			cur.clause = ClauseJoinOn
+		}
+	case "USING":
+		cur.clause = ClauseJoinOn
+	case "WHERE", "HAVING":
+		cur.clause = ClauseWhere
+	case "GROUP":
+		cur.clause = ClauseGroupBy
+	case "ORDER":
+		cur.clause = ClauseOrderBy
+	case "SET":
+		if cur.statement == "UPDATE" {
+			cur.clause = ClauseSet
+		}
+	case "VALUES", "VALUE":
+		cur.clause = ClauseValues
+	case "LIMIT":
+		cur.clause = ClauseLimit
+	case "USE":
+		if first {
+			cur.clause = ClauseUse
+		}
+	}
+}
+
+// scope returns the scope after done, the tokens advanced over.
+func (p *scopeParser) scope(done []Token) Scope {
+	cur := p.levels[len(p.levels)-1]
+	scope := Scope{Statement: cur.statement, Clause: cur.clause}
+	if len(done) > 0 {
+		prev := done[len(done)-1]
+		scope.prevWord = strings.ToUpper(prev.Value)
+		scope.expectTable = cur.clause == ClauseFrom &&
+			(tableContextKeywords[scope.prevWord] || prev.Value == ",")
+
+		if cur.clause == ClauseJoinOn && cur.joined > 0 && (scope.prevWord == "ON" || scope.prevWord == "AND") {
+			if ref, _, ok := parseTableRef(p.all, cur.joined); ok {
+				if cols, isCTE := p.ctes[strings.ToLower(ref.Name)]; isCTE && ref.Schema == "" {
+					ref.Columns = cols
+				}
+				scope.Joined = &ref
+			}
+		}
+	}
+
+	// Tables of the innermost query first, then of the enclosing ones
+	for i := len(p.levels) - 1; i >= 0; i-- {
+		if p.levels[i].query {
+			scope.Tables = append(scope.Tables, p.levelTables(p.levels[i].start)...)
+		}
+	}
+	return scope
+}
+
+// levelTables returns the tables of the query level starting at start.
+func (p *scopeParser) levelTables(start int) []TableRef {
+	if tables, ok := p.tables[start]; ok {
+		return tables
+	}
+	from, end := 0, len(p.all)
+	if start >= 0 {
+		from = start + 1
+		end = matchingParen(p.all, start)
+	}
+	tables := tablesOf(p.all[from:end], p.ctes)
+	p.tables[start] = tables
+	return tables
+}
+
+// isStatementKeyword reports whether word starts a statement.
+func isStatementKeyword(word string) bool {
+	switch word {
+	case "SELECT", "INSERT", "REPLACE", "UPDATE", "DELETE", "WITH", "USE",
+		"DESC", "DESCRIBE", "TRUNCATE", "DROP", "CREATE", "ALTER", "SHOW":
+		return true
+	}
+	return false
+}
+
+// tablesOf returns the tables referenced at the top level of a query,
+// skipping those of its subqueries.
+func tablesOf(tokens []Token, ctes map[string][]string) []TableRef {
+	var tables []TableRef
+	depth := 0
+	for i, t := range tokens {
+		switch t.Value {
+		case "(":
+			depth++
+			continue
+		case ")":
+			depth--
+			continue
+		}
+		upper := strings.ToUpper(t.Value)
+		if depth != 0 || !tableContextKeywords[upper] || i+1 >= len(tokens) {
+			continue
+		}
+		tables = appendTableRefs(tables, tokens, i+1, upper == "FROM", ctes)
+	}
+	return tables
+}
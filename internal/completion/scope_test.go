@@ -0,0 +1,173 @@
+package completion
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string // | marks the cursor
+		clause    Clause
+		context   CompletionContext
+		tables    []TableRef
+		joined    *TableRef
+	}{
+		{
+			name:      "insert column list",
+			statement: "INSERT INTO users (|",
+			clause:    ClauseInsertColumns,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "insert values",
+			statement: "INSERT INTO users (id) VALUES (|",
+			clause:    ClauseValues,
+			context:   ContextKeywordOrTable,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "update set",
+			statement: "UPDATE users SET |",
+			clause:    ClauseSet,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "update set partial word",
+			statement: "UPDATE users u SET na|",
+			clause:    ClauseSet,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users", Alias: "u"}},
+		},
+		{
+			name:      "on duplicate key update",
+			statement: "INSERT INTO users (id) VALUES (1) ON DUPLICATE KEY UPDATE |",
+			clause:    ClauseSet,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "join on",
+			statement: "SELECT * FROM users u JOIN orders o ON |",
+			clause:    ClauseJoinOn,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users", Alias: "u"}, {Name: "orders", Alias: "o"}},
+			joined:    &TableRef{Name: "orders", Alias: "o"},
+		},
+		{
+			name:      "join on after a condition",
+			statement: "SELECT * FROM users u JOIN orders o ON o.user_id = u.id AND |",
+			clause:    ClauseJoinOn,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users", Alias: "u"}, {Name: "orders", Alias: "o"}},
+			joined:    &TableRef{Name: "orders", Alias: "o"},
+		},
+		{
+			name:      "join on within a condition",
+			statement: "SELECT * FROM users u JOIN orders o ON o.user_id = |",
+			clause:    ClauseJoinOn,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users", Alias: "u"}, {Name: "orders", Alias: "o"}},
+		},
+		{
+			name:      "table after join",
+			statement: "SELECT * FROM users u JOIN |",
+			clause:    ClauseFrom,
+			context:   ContextTable,
+			tables:    []TableRef{{Name: "users", Alias: "u"}},
+		},
+		{
+			name:      "second table of a list",
+			statement: "SELECT * FROM users u, ord|",
+			clause:    ClauseFrom,
+			context:   ContextTable,
+			tables:    []TableRef{{Name: "users", Alias: "u"}, {Name: "ord"}},
+		},
+		{
+			name:      "select list with from after cursor",
+			statement: "SELECT | FROM users",
+			clause:    ClauseSelect,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "order by desc is not a table",
+			statement: "SELECT * FROM users ORDER BY id DESC |",
+			clause:    ClauseOrderBy,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "subquery sees its own tables first",
+			statement: "SELECT * FROM users u WHERE u.id IN (SELECT | FROM orders o)",
+			clause:    ClauseSelect,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "orders", Alias: "o"}, {Name: "users", Alias: "u"}},
+		},
+		{
+			name:      "back in outer query after subquery",
+			statement: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders) AND |",
+			clause:    ClauseWhere,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "unclosed subquery",
+			statement: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE |",
+			clause:    ClauseWhere,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "orders"}, {Name: "users"}},
+		},
+		{
+			name:      "function arguments keep the clause",
+			statement: "SELECT * FROM users WHERE LOWER(|",
+			clause:    ClauseWhere,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "users"}},
+		},
+		{
+			name:      "after cte",
+			statement: "WITH recent AS (SELECT id FROM orders) SELECT | FROM recent",
+			clause:    ClauseSelect,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "recent", Columns: []string{"id"}}},
+		},
+		{
+			name:      "inside cte body",
+			statement: "WITH recent AS (SELECT * FROM orders WHERE |) SELECT * FROM recent",
+			clause:    ClauseWhere,
+			context:   ContextColumn,
+			tables:    []TableRef{{Name: "orders"}, {Name: "recent"}},
+		},
+		{
+			name:      "use",
+			statement: "USE |",
+			clause:    ClauseUse,
+			context:   ContextDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := len([]rune(tt.statement[:strings.Index(tt.statement, "|")]))
+			statement := strings.Replace(tt.statement, "|", "", 1)
+			scope := ParseScope(statement, cursor)
+			if scope.Clause != tt.clause {
+				t.Errorf("Clause = %v, want %v", scope.Clause, tt.clause)
+			}
+			if got := scope.Context(); got != tt.context {
+				t.Errorf("Context() = %v, want %v", got, tt.context)
+			}
+			if !reflect.DeepEqual(scope.Tables, tt.tables) {
+				t.Errorf("Tables = %+v, want %+v", scope.Tables, tt.tables)
+			}
+			if !reflect.DeepEqual(scope.Joined, tt.joined) {
+				t.Errorf("Joined = %+v, want %+v", scope.Joined, tt.joined)
+			}
+		})
+	}
+}
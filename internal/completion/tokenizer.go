@@ -21,6 +21,7 @@ const (
 	TokenNumber
 	TokenPunctuation
 	TokenWhitespace
+	TokenComment
 )
 
 // Tokenize splits SQL input into tokens for completion analysis.
@@ -43,6 +44,14 @@ func Tokenize(input string) []Token {
 				Type:  TokenWhitespace,
 			})
 
+		case isCommentStart(runes, i):
+			start := i
+			i = scanComment(runes, i)
+			tokens = append(tokens, Token{
+				Value: string(runes[start:i]),
+				Type:  TokenComment,
+			})
+
 		case ch == '\'' || ch == '"':
 			quote := ch
 			start := i
@@ -61,24 +70,15 @@ func Tokenize(input string) []Token {
 				Type:  TokenString,
 			})
 
-		case ch == '`':
-			start := i
-			i++
-			for i < len(runes) && runes[i] != '`' {
-				i++
-			}
-			if i < len(runes) {
-				i++
-			}
-			tokens = append(tokens, Token{
-				Value: string(runes[start:i]),
-				Type:  TokenWord,
-			})
-
-		case unicode.IsLetter(ch) || ch == '_':
+		case ch == '`' || unicode.IsLetter(ch) || ch == '_':
+			// Qualified names such as db.table or t.col form a single word
 			start := i
-			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+			i = scanIdentifier(runes, i)
+			for i < len(runes) && runes[i] == '.' {
 				i++
+				if i < len(runes) && isIdentifierStart(runes[i]) {
+					i = scanIdentifier(runes, i)
+				}
 			}
 			tokens = append(tokens, Token{
 				Value: string(runes[start:i]),
@@ -127,6 +127,61 @@ func Tokenize(input string) []Token {
 	return tokens
 }
 
+// isCommentStart reports whether a comment starts at i: "#", "/*", or "--"
+// followed by whitespace or the end of input.
+func isCommentStart(runes []rune, i int) bool {
+	switch runes[i] {
+	case '#':
+		return true
+	case '/':
+		return i+1 < len(runes) && runes[i+1] == '*'
+	case '-':
+		return i+1 < len(runes) && runes[i+1] == '-' &&
+			(i+2 == len(runes) || unicode.IsSpace(runes[i+2]))
+	}
+	return false
+}
+
+// scanComment returns the position after the comment starting at i. Line
+// comments end before the newline; an unclosed block comment runs to the
+// end of input.
+func scanComment(runes []rune, i int) int {
+	if runes[i] == '/' {
+		i += 2
+		for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+			i++
+		}
+		return min(i+2, len(runes))
+	}
+	for i < len(runes) && runes[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+func isIdentifierStart(ch rune) bool {
+	return ch == '`' || unicode.IsLetter(ch) || ch == '_'
+}
+
+// scanIdentifier returns the position after the plain or backquoted
+// identifier starting at i.
+func scanIdentifier(runes []rune, i int) int {
+	if runes[i] == '`' {
+		i++
+		for i < len(runes) && runes[i] != '`' {
+			i++
+		}
+		if i < len(runes) {
+			i++
+		}
+		return i
+	}
+	for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+		i++
+	}
+	return i
+}
+
 // GetLastWord returns the last incomplete word from input for completion.
 func GetLastWord(input string) string {
 	if len(input) == 0 {
@@ -150,11 +205,34 @@ func GetLastWord(input string) string {
 	return string(runes[start:end])
 }
 
-// GetNonWhitespaceTokens returns only non-whitespace tokens.
+// GetQualifier returns the name before the dot preceding the last word,
+// e.g. "shop" for "SELECT * FROM shop.ord". It is empty when the last
+// word is not qualified.
+func GetQualifier(input string) string {
+	runes := []rune(input)
+	end := len(runes) - len([]rune(GetLastWord(input)))
+	if end == 0 || runes[end-1] != '.' {
+		return ""
+	}
+
+	start := end - 1
+	for start > 0 {
+		ch := runes[start-1]
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '`' {
+			start--
+		} else {
+			break
+		}
+	}
+	return strings.Trim(string(runes[start:end-1]), "`")
+}
+
+// GetNonWhitespaceTokens returns the tokens other than whitespace and
+// comments.
 func GetNonWhitespaceTokens(tokens []Token) []Token {
 	var result []Token
 	for _, t := range tokens {
-		if t.Type != TokenWhitespace {
+		if t.Type != TokenWhitespace && t.Type != TokenComment {
 			result = append(result, t)
 		}
 	}
@@ -0,0 +1,65 @@
+package completion
+
+import "testing"
+
+func TestTokenizeComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{
+			name:  "line comments",
+			input: "a -- note\n# hash",
+			want: []Token{
+				{Value: "a", Type: TokenWord},
+				{Value: " ", Type: TokenWhitespace},
+				{Value: "-- note", Type: TokenComment},
+				{Value: "\n", Type: TokenWhitespace},
+				{Value: "# hash", Type: TokenComment},
+			},
+		},
+		{
+			name:  "double minus without space",
+			input: "1--2",
+			want: []Token{
+				{Value: "1", Type: TokenNumber},
+				{Value: "-", Type: TokenOperator},
+				{Value: "-", Type: TokenOperator},
+				{Value: "2", Type: TokenNumber},
+			},
+		},
+		{
+			name:  "block comment",
+			input: "/* x; y */b",
+			want: []Token{
+				{Value: "/* x; y */", Type: TokenComment},
+				{Value: "b", Type: TokenWord},
+			},
+		},
+		{
+			name:  "unclosed block comment",
+			input: "/* x",
+			want:  []Token{{Value: "/* x", Type: TokenComment}},
+		},
+		{
+			name:  "comment marker inside string",
+			input: "'-- x'",
+			want:  []Token{{Value: "'-- x'", Type: TokenString}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Tokenize(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Tokenize(%q)[%d] = %v, want %v", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// Span is a range of runes in a statement, End exclusive.
+type Span struct {
+	Start int
+	End   int
+}
+
+// located is a token with its rune offsets in the statement.
+type located struct {
+	Token
+	start int
+	end   int
+	// query is set when the token is outside parentheses or in a
+	// subquery, rather than in a function call or a column list.
+	query bool
+}
+
+// validatedStatements are the statements whose names are checked. DDL
+// names objects that do not exist yet.
+var validatedStatements = map[string]bool{
+	"SELECT": true, "INSERT": true, "REPLACE": true, "UPDATE": true,
+	"DELETE": true, "WITH": true,
+}
+
+// Unknown returns the tables and columns named from rune offset from on
+// that do not exist. It only consults metadata that is already cached and
+// never waits for the database: a name is reported only when the tables it
+// could belong to are cached.
+func (c *SQLCompleter) Unknown(statement string, from int) []Span {
+	if c.cache == nil || c.dialect == nil {
+		return nil
+	}
+
+	tokens := locate(statement)
+	all := tokensOf(tokens)
+	aliases := c.outputAliases(tokens)
+
+	// The scope of each word is that of a cursor typing it, so the parser
+	// advances past a token only after it has been checked.
+	p := newScopeParser(all)
+	var spans []Span
+	for i, t := range tokens {
+		if t.Type == TokenWord && t.start >= from {
+			if span, ok := c.unknownName(p, tokens, i, aliases); ok {
+				spans = append(spans, span)
+			}
+		}
+		p.advance(i, t.Token)
+	}
+	return spans
+}
+
+// unknownName checks the word at tokens[i] against the cached metadata. p
+// has advanced up to the word.
+func (c *SQLCompleter) unknownName(p *scopeParser, tokens []located, i int, aliases map[string]bool) (Span, bool) {
+	t := tokens[i]
+	var prev, next located
+	if i > 0 {
+		prev = tokens[i-1]
+	}
+	if i+1 < len(tokens) {
+		next = tokens[i+1]
+	}
+	prevUpper := strings.ToUpper(prev.Value)
+	switch {
+	case prev.Value == "@" || prev.Value == ":":
+		// Variables and named parameters
+		return Span{}, false
+	case prevUpper == "AS" || prevUpper == "OVER" || prevUpper == "USING":
+		// Aliases, types, named windows and character sets
+		return Span{}, false
+	case strings.EqualFold(next.Value, "AS") && i+2 < len(tokens) && tokens[i+2].Value == "(":
+		// CTE and window definitions
+		return Span{}, false
+	}
+
+	ctes := p.ctes
+	scope := p.scope(p.all[:i])
+	if !validatedStatements[scope.Statement] {
+		return Span{}, false
+	}
+
+	switch scope.Context() {
+	case ContextTable:
+		insert := scope.Statement == "INSERT" || scope.Statement == "REPLACE"
+		// A comma in INSERT ... SET separates assignments, not tables
+		if !t.query || !(tableContextKeywords[prevUpper] || (prev.Value == "," && !insert)) {
+			return Span{}, false
+		}
+		if c.unknownTable(t.Value, ctes) {
+			return Span{t.start, t.end}, true
+		}
+	case ContextColumn:
+		if next.Value == "(" || c.isKeyword(t.Value) {
+			return Span{}, false
+		}
+		if strings.Contains(t.Value, ".") {
+			return c.unknownQualifiedColumn(t, scope.Tables, ctes)
+		}
+		if aliases[strings.ToLower(strings.Trim(t.Value, "`"))] {
+			return Span{}, false
+		}
+		if c.unknownColumn(strings.Trim(t.Value, "`"), scope.Tables, ctes) {
+			return Span{t.start, t.end}, true
+		}
+	}
+	return Span{}, false
+}
+
+// unknownTable reports whether name is missing from the cached table list
+// of its schema.
+func (c *SQLCompleter) unknownTable(name string, ctes map[string][]string) bool {
+	if c.isKeyword(name) {
+		return false
+	}
+	schema, table := db.SplitQualifiedName(name)
+	if table == "" {
+		return false
+	}
+	if _, isCTE := ctes[strings.ToLower(table)]; isCTE && schema == "" {
+		return false
+	}
+	tables, ok := c.cache.CachedTables(schema)
+	if !ok {
+		return false
+	}
+	for _, t := range tables {
+		if strings.EqualFold(t, table) {
+			return false
+		}
+	}
+	return true
+}
+
+// unknownQualifiedColumn checks a column qualified with a table name or
+// alias, as in "o.total". Only the column part is reported.
+func (c *SQLCompleter) unknownQualifiedColumn(t located, tables []TableRef, ctes map[string][]string) (Span, bool) {
+	qualifier, name := db.SplitQualifiedName(t.Value)
+	if name == "" {
+		return Span{}, false
+	}
+	table, ok := qualifiedTable(tables, qualifier)
+	if !ok {
+		return Span{}, false
+	}
+	columns, ok := c.cachedColumns(table, ctes)
+	if !ok || hasColumn(columns, name) {
+		return Span{}, false
+	}
+	dot := strings.LastIndex(t.Value, ".")
+	return Span{t.end - len([]rune(t.Value[dot+1:])), t.end}, true
+}
+
+// unknownColumn reports whether an unqualified column is missing from all
+// tables in scope. Derived tables, CTEs and tables whose columns are not
+// cached may have any column.
+func (c *SQLCompleter) unknownColumn(name string, tables []TableRef, ctes map[string][]string) bool {
+	if len(tables) == 0 {
+		return false
+	}
+	for _, table := range tables {
+		if table.Matches(name) {
+			return false
+		}
+		columns, ok := c.cachedColumns(table, ctes)
+		if !ok || hasColumn(columns, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedColumns returns the column names of a base table if they are
+// cached.
+func (c *SQLCompleter) cachedColumns(table TableRef, ctes map[string][]string) ([]string, bool) {
+	if table.Name == "" || table.Columns != nil {
+		return nil, false
+	}
+	if _, isCTE := ctes[strings.ToLower(table.Name)]; isCTE && table.Schema == "" {
+		return nil, false
+	}
+	cols, ok := c.cache.CachedColumns(table.Schema, table.Name)
+	if !ok {
+		return nil, false
+	}
+	columns := make([]string, len(cols))
+	for i, col := range cols {
+		columns[i] = col.Name
+	}
+	return columns, true
+}
+
+// isKeyword reports whether word is a keyword or a function name rather
+// than an identifier. Quoted words are always identifiers.
+func (c *SQLCompleter) isKeyword(word string) bool {
+	if strings.Contains(word, "`") {
+		return false
+	}
+	upper := strings.ToUpper(word)
+	if c.dialect.IsReserved(upper) {
+		return true
+	}
+	for _, kw := range c.dialect.Keywords() {
+		if kw == upper {
+			return true
+		}
+	}
+	_, ok := db.FindFunction(c.dialect.Functions(), upper)
+	return ok
+}
+
+// outputAliases returns the lower-case aliases defined in a statement, so
+// that "ORDER BY total" is not taken for a missing column when total
+// names a select list item. An alias follows AS or directly follows an
+// expression.
+func (c *SQLCompleter) outputAliases(tokens []located) map[string]bool {
+	aliases := make(map[string]bool)
+	for i := 1; i < len(tokens); i++ {
+		t, prev := tokens[i], tokens[i-1]
+		if t.Type != TokenWord {
+			continue
+		}
+		alias := strings.EqualFold(prev.Value, "AS") || prev.Value == ")" ||
+			prev.Type == TokenString || prev.Type == TokenNumber ||
+			(isName(prev.Token) && !c.isKeyword(prev.Value))
+		if alias {
+			aliases[strings.ToLower(strings.Trim(t.Value, "`"))] = true
+		}
+	}
+	return aliases
+}
+
+// locate tokenizes statement, dropping whitespace and comments, and
+// records where each token is.
+func locate(statement string) []located {
+	var tokens []located
+	var parens []bool
+	offset := 0
+	for _, t := range Tokenize(statement) {
+		start := offset
+		offset += len([]rune(t.Value))
+		if t.Type == TokenWhitespace || t.Type == TokenComment {
+			continue
+		}
+		tokens = append(tokens, located{Token: t, start: start, end: offset,
+			query: len(parens) == 0 || parens[len(parens)-1]})
+		n := len(tokens)
+		// Whether the parenthesis opens a query is known at the next token
+		if n > 1 && tokens[n-2].Value == "(" {
+			upper := strings.ToUpper(t.Value)
+			parens[len(parens)-1] = upper == "SELECT" || upper == "WITH"
+			tokens[n-1].query = parens[len(parens)-1]
+		}
+		switch t.Value {
+		case "(":
+			parens = append(parens, false)
+		case ")":
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+		}
+	}
+	return tokens
+}
+
+func tokensOf(tokens []located) []Token {
+	result := make([]Token, len(tokens))
+	for i, t := range tokens {
+		result[i] = t.Token
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package completion
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/cache"
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+// schemaStub serves a fixed schema. Other methods are never called.
+type schemaStub struct {
+	db.MetadataProvider
+	columns map[string][]db.ColumnInfo
+}
+
+func (s schemaStub) GetTables(ctx context.Context, schema string) ([]string, error) {
+	var tables []string
+	for table := range s.columns {
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func (s schemaStub) GetAllColumns(ctx context.Context, schema string) (map[string][]db.ColumnInfo, error) {
+	return s.columns, nil
+}
+
+// newValidationCompleter returns a completer whose cache holds the table
+// list and, if columns is set, the columns of every table.
+func newValidationCompleter(t *testing.T, columns bool) *SQLCompleter {
+	t.Helper()
+	provider := schemaStub{columns: map[string][]db.ColumnInfo{
+		"users":  {{Name: "id"}, {Name: "name"}, {Name: "email"}},
+		"orders": {{Name: "id"}, {Name: "user_id"}, {Name: "total"}},
+	}}
+	c := cache.NewMetadataCache(provider, time.Minute)
+	ctx := context.Background()
+	if _, err := c.GetTables(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if columns {
+		if _, err := c.GetAllColumns(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewSQLCompleter(c, mysql.NewDialect())
+}
+
+func unknownNames(c *SQLCompleter, statement string, from int) []string {
+	runes := []rune(statement)
+	var names []string
+	for _, span := range c.Unknown(statement, from) {
+		names = append(names, string(runes[span.Start:span.End]))
+	}
+	return names
+}
+
+func TestUnknown(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  []string
+	}{
+		{"unknown table", "SELECT * FROM ordres", []string{"ordres"}},
+		{"unknown column", "SELECT nme FROM users", []string{"nme"}},
+		{"unknown qualified column", "SELECT u.nme FROM users u", []string{"nme"}},
+		{"quoted column", "SELECT `nme` FROM users", []string{"`nme`"}},
+		{"join", "SELECT o.total, u.name FROM orders o JOIN users u ON o.user_id = u.id", nil},
+		{"columns of any table in scope", "SELECT name, total FROM users, orders", nil},
+		{"select aliases", "SELECT COUNT(*) AS cnt, total t FROM orders GROUP BY t ORDER BY cnt", nil},
+		{"functions and keywords", "SELECT COALESCE(name, 'x'), NOW() - INTERVAL 1 DAY FROM users WHERE email IS NOT NULL", nil},
+		{"subquery", "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE totl > 1)", []string{"totl"}},
+		{"correlated subquery", "SELECT * FROM users u WHERE EXISTS (SELECT 1 FROM orders WHERE user_id = u.id AND name <> '')", nil},
+		{"variables", "SELECT * FROM users WHERE nme = @name", []string{"nme"}},
+		{"insert columns", "INSERT INTO users (id, nme) VALUES (1, 'a')", []string{"nme"}},
+		{"insert set", "INSERT INTO users SET id = 1, name = 'a'", nil},
+		{"update", "UPDATE users SET nme = 'a' WHERE id = 1", []string{"nme"}},
+		{"CTE", "WITH recent AS (SELECT id FROM orders) SELECT anything FROM recent", nil},
+		{"derived table", "SELECT x FROM (SELECT id FROM users) d", nil},
+		{"FROM in a function", "SELECT EXTRACT(YEAR FROM id), CONVERT(name USING utf8mb4) FROM orders", []string{"name"}},
+		{"DDL", "CREATE TABLE ordres (id INT)", nil},
+		{"schema not cached", "SELECT * FROM shop.users", nil},
+	}
+
+	c := newValidationCompleter(t, true)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unknownNames(c, tt.statement, 0)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Unknown(%q) = %q, want %q", tt.statement, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnknownOnlyFromOffset(t *testing.T) {
+	c := newValidationCompleter(t, true)
+	statement := "SELECT nme\nFROM ordres"
+	got := unknownNames(c, statement, len("SELECT nme\n"))
+	if want := []string{"ordres"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown() = %q, want %q", got, want)
+	}
+}
+
+func TestUnknownWithoutCachedColumns(t *testing.T) {
+	c := newValidationCompleter(t, false)
+	got := unknownNames(c, "SELECT nme FROM ordres JOIN users", 0)
+	if want := []string{"ordres"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown() = %q, want %q", got, want)
+	}
+}
+
+func TestUnknownLongStatement(t *testing.T) {
+	c := newValidationCompleter(t, true)
+	parts := make([]string, 100)
+	for i := range parts {
+		parts[i] = "SELECT u.nme FROM users u WHERE u.id IN (SELECT user_id FROM orders WHERE totl > 1)"
+	}
+	got := unknownNames(c, strings.Join(parts, "\nUNION ALL\n"), 0)
+	if len(got) != 2*len(parts) {
+		t.Errorf("Unknown() found %d names, want %d", len(got), 2*len(parts))
+	}
+}
+
+func TestScopeParserMatchesParseScope(t *testing.T) {
+	statements := []string{
+		"SELECT o.total FROM orders o JOIN users u ON u.id = o.user_id WHERE u.name = 'a' ORDER BY 1",
+		"WITH recent AS (SELECT id FROM orders) SELECT * FROM recent r WHERE id IN (SELECT user_id FROM orders)",
+		"INSERT INTO users (id, name) VALUES (1, 'a') ON DUPLICATE KEY UPDATE name = VALUES(name); UPDATE users SET name = 'b'",
+	}
+	for _, statement := range statements {
+		tokens := locate(statement)
+		p := newScopeParser(tokensOf(tokens))
+		for i, tok := range tokens {
+			if tok.Type == TokenWord {
+				got, want := p.scope(p.all[:i]), ParseScope(statement, tok.end)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("scope at %q in %q = %+v, want %+v", tok.Value, statement, got, want)
+				}
+			}
+			p.advance(i, tok.Token)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package completion
+
+import (
+	"strings"
+)
+
+// ValueTarget is a column whose value is being typed, as in
+// "WHERE status = '|" or "status IN ('paid', |".
+type ValueTarget struct {
+	// Column is the column name as written, possibly qualified.
+	Column string
+	// Quote is the opening quote already typed, or 0 if none.
+	Quote rune
+	// Prefix is the text typed after the opening quote.
+	Prefix string
+}
+
+// DetectValueTarget reports whether input ends where a literal compared
+// with a column is expected: after =, != or <>, or in an IN list.
+func DetectValueTarget(input string) (ValueTarget, bool) {
+	tokens := GetNonWhitespaceTokens(Tokenize(input))
+	if len(tokens) == 0 {
+		return ValueTarget{}, false
+	}
+
+	var target ValueTarget
+	i := len(tokens) - 1
+	last := tokens[i]
+	if last.Type == TokenString {
+		quote, body, open := openString(last.Value)
+		if !open {
+			return ValueTarget{}, false
+		}
+		target.Quote, target.Prefix = quote, body
+		i--
+	}
+	if i < 1 {
+		return ValueTarget{}, false
+	}
+
+	switch tokens[i].Value {
+	case "=", "!=", "<>":
+		if tokens[i-1].Type == TokenWord {
+			target.Column = tokens[i-1].Value
+			return target, true
+		}
+		return ValueTarget{}, false
+	}
+
+	// Skip the values already in an IN list
+	for i >= 0 && (tokens[i].Value == "," || tokens[i].Type == TokenString || tokens[i].Type == TokenNumber) {
+		i--
+	}
+	if i < 2 || tokens[i].Value != "(" || !strings.EqualFold(tokens[i-1].Value, "IN") {
+		return ValueTarget{}, false
+	}
+	column := i - 2
+	if strings.EqualFold(tokens[column].Value, "NOT") && column > 0 {
+		column--
+	}
+	if tokens[column].Type != TokenWord {
+		return ValueTarget{}, false
+	}
+	target.Column = tokens[column].Value
+	return target, true
+}
+
+// openString reports whether the string token s is missing its closing
+// quote and returns the quote and the text after it.
+func openString(s string) (rune, string, bool) {
+	runes := []rune(s)
+	quote := runes[0]
+	i := 1
+	for i < len(runes) && runes[i] != quote {
+		if runes[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	if i < len(runes) {
+		return 0, "", false
+	}
+	return quote, string(runes[1:]), true
+}
+
+// QuoteValue returns value as a string literal completing target: the
+// opening quote is added unless it was typed already.
+func QuoteValue(target ValueTarget, value string) string {
+	quote := target.Quote
+	if quote == 0 {
+		quote = '\''
+	}
+	q := string(quote)
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, q, q+q)
+	if target.Quote != 0 {
+		return escaped + q
+	}
+	return q + escaped + q
+}
@@ -0,0 +1,126 @@
+package completion
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/cache"
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+func TestDetectValueTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   ValueTarget
+		wantOK bool
+	}{
+		{
+			name:   "open quote after equals",
+			input:  "SELECT * FROM orders WHERE status = '",
+			want:   ValueTarget{Column: "status", Quote: '\''},
+			wantOK: true,
+		},
+		{
+			name:   "partial value",
+			input:  "SELECT * FROM orders o WHERE o.status <> 'pa",
+			want:   ValueTarget{Column: "o.status", Quote: '\'', Prefix: "pa"},
+			wantOK: true,
+		},
+		{
+			name:   "no quote yet",
+			input:  "UPDATE orders SET status = ",
+			want:   ValueTarget{Column: "status"},
+			wantOK: true,
+		},
+		{
+			name:   "in list",
+			input:  "SELECT * FROM orders WHERE status NOT IN ('paid', \"sh",
+			want:   ValueTarget{Column: "status", Quote: '"', Prefix: "sh"},
+			wantOK: true,
+		},
+		{
+			name:  "closed string",
+			input: "SELECT * FROM orders WHERE status = 'paid'",
+		},
+		{
+			name:  "function argument",
+			input: "SELECT CONCAT('a', ",
+		},
+		{
+			name:  "column being typed",
+			input: "SELECT * FROM orders WHERE sta",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DetectValueTarget(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("DetectValueTarget(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestQuoteValue(t *testing.T) {
+	tests := []struct {
+		target ValueTarget
+		value  string
+		want   string
+	}{
+		{ValueTarget{}, "paid", "'paid'"},
+		{ValueTarget{Quote: '\''}, "paid", "paid'"},
+		{ValueTarget{Quote: '\''}, "it's", "it''s'"},
+		{ValueTarget{Quote: '"'}, `a\b`, `a\\b"`},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteValue(tt.target, tt.value); got != tt.want {
+			t.Errorf("QuoteValue(%+v, %q) = %q, want %q", tt.target, tt.value, got, tt.want)
+		}
+	}
+}
+
+// valuesStub serves the columns of one table and records which columns
+// are sampled.
+type valuesStub struct {
+	db.MetadataProvider
+	sampled *[]string
+}
+
+func (s valuesStub) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	return []db.ColumnInfo{
+		{Name: "id", Key: "PRI"},
+		{Name: "code", Key: "UNI"},
+		{Name: "status", Key: "MUL"},
+		{Name: "note"},
+	}, nil
+}
+
+func (s valuesStub) GetColumnValues(ctx context.Context, schema, tableName, column string, limit int) ([]string, error) {
+	*s.sampled = append(*s.sampled, column)
+	return []string{"paid"}, nil
+}
+
+func TestValueCandidatesSampleNonUniqueIndexes(t *testing.T) {
+	var sampled []string
+	c := NewSQLCompleter(cache.NewMetadataCache(valuesStub{sampled: &sampled}, time.Minute), mysql.NewDialect())
+	tables := []TableRef{{Name: "orders"}}
+
+	for _, column := range []string{"id", "code", "note"} {
+		if got := c.getValueCandidates(context.Background(), tables, ValueTarget{Column: column}); len(got) != 0 {
+			t.Errorf("getValueCandidates(%s) = %v, want none", column, got)
+		}
+	}
+	got := c.getValueCandidates(context.Background(), tables, ValueTarget{Column: "status", Quote: '\''})
+	if !reflect.DeepEqual(got, []string{"paid'"}) {
+		t.Errorf("getValueCandidates(status) = %v, want [paid']", got)
+	}
+	if !reflect.DeepEqual(sampled, []string{"status"}) {
+		t.Errorf("sampled columns = %v, want [status]", sampled)
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 )
 
 // Connector defines the interface for database connection and query execution.
@@ -14,21 +15,98 @@ type Connector interface {
 	Ping(ctx context.Context) error
 	DB() *sql.DB
 	GetCurrentDatabase(ctx context.Context) (string, error)
+	Begin(ctx context.Context) (Transaction, error)
+	Stream(ctx context.Context, query string, args []interface{}, w RowWriter) error
+}
+
+// RowWriter receives query results one row at a time.
+// The values slice passed to WriteRow is reused between calls.
+type RowWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+}
+
+// ErrTransactionAborted wraps statement errors that may have rolled back
+// the whole transaction, such as a deadlock, rather than the statement.
+var ErrTransactionAborted = errors.New("transaction rolled back")
+
+// Transaction defines the interface for executing queries inside a transaction.
+type Transaction interface {
+	ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*QueryResult, error)
+	Commit() error
+	Rollback() error
+}
+
+// BulkLoader is implemented by connectors that can load a delimited file
+// on the server side (e.g. LOAD DATA LOCAL INFILE).
+type BulkLoader interface {
+	BulkLoadAllowed(ctx context.Context) bool
+	BulkLoad(ctx context.Context, req BulkLoadRequest) (int64, error)
+}
+
+// SnapshotReader is implemented by connectors that can run several reads
+// against one consistent snapshot of the database.
+type SnapshotReader interface {
+	BeginSnapshot(ctx context.Context) (Snapshot, error)
+}
+
+// Snapshot streams queries from a consistent read view held on a single
+// connection. Close ends the read view and releases the connection.
+type Snapshot interface {
+	Stream(ctx context.Context, query string, args []interface{}, w RowWriter) error
+	Close() error
 }
 
 // MetadataProvider defines the interface for retrieving database metadata.
 type MetadataProvider interface {
-	GetTables(ctx context.Context) ([]string, error)
-	GetColumns(ctx context.Context, tableName string) ([]ColumnInfo, error)
+	// Table-level methods take a schema name; an empty schema means the
+	// current database.
+	GetTables(ctx context.Context, schema string) ([]string, error)
+	GetColumns(ctx context.Context, schema, tableName string) ([]ColumnInfo, error)
+	// GetAllColumns returns the columns of every table in a schema, keyed
+	// by table name, using a single query.
+	GetAllColumns(ctx context.Context, schema string) (map[string][]ColumnInfo, error)
+	// GetSchemaChecksum returns a value that changes when the tables or
+	// columns of a schema change.
+	GetSchemaChecksum(ctx context.Context, schema string) (string, error)
 	GetDatabases(ctx context.Context) ([]string, error)
+	GetIndexes(ctx context.Context, schema, tableName string) ([]IndexInfo, error)
+	// GetForeignKeys returns the foreign keys of a table and those
+	// referencing it from other tables.
+	GetForeignKeys(ctx context.Context, schema, tableName string) ([]ForeignKeyInfo, error)
+	GetViews(ctx context.Context, schema string) ([]string, error)
+	GetRoutines(ctx context.Context, schema string) ([]RoutineInfo, error)
+	GetTriggers(ctx context.Context, schema string) ([]TriggerInfo, error)
+	GetTableStatus(ctx context.Context, schema, tableName string) (*TableStatus, error)
+	// GetDDL returns the definition of an object. An empty objectType
+	// looks up the kind of the named object first.
+	GetDDL(ctx context.Context, objectType ObjectType, schema, name string) (*ObjectDDL, error)
+	// GetColumnValues returns up to limit distinct non-NULL values of a
+	// column, used to suggest literals.
+	GetColumnValues(ctx context.Context, schema, tableName, column string, limit int) ([]string, error)
 }
 
 // Dialect defines database-specific SQL syntax and keywords.
 type Dialect interface {
 	Name() string
 	Keywords() []string
+	// Functions returns the catalog of built-in functions.
+	Functions() []FunctionInfo
+	// IsReserved reports whether word must be quoted as an identifier.
+	IsReserved(word string) bool
 	QuoteIdentifier(name string) string
-	GetTablesQuery() string
-	GetColumnsQuery(tableName string) string
+	GetTablesQuery(schema string) (string, []interface{})
+	GetColumnsQuery(schema, tableName string) (string, []interface{})
+	GetAllColumnsQuery(schema string) (string, []interface{})
+	GetSchemaChecksumQuery(schema string) (string, []interface{})
 	GetDatabasesQuery() string
+	GetIndexesQuery(schema, tableName string) (string, []interface{})
+	GetForeignKeysQuery(schema, tableName string) (string, []interface{})
+	GetViewsQuery(schema string) (string, []interface{})
+	GetRoutinesQuery(schema string) (string, []interface{})
+	GetTriggersQuery(schema string) (string, []interface{})
+	GetTableStatusQuery(schema, tableName string) (string, []interface{})
+	GetObjectTypeQuery(schema, name string) (string, []interface{})
+	GetDDLQuery(objectType ObjectType, schema, name string) string
+	GetColumnValuesQuery(schema, tableName, column string, limit int) string
 }
@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// BulkLoadAllowed reports whether the server accepts LOAD DATA LOCAL INFILE.
+func (c *Connector) BulkLoadAllowed(ctx context.Context) bool {
+	var enabled int
+	if err := c.db.QueryRowContext(ctx, "SELECT @@GLOBAL.local_infile").Scan(&enabled); err != nil {
+		return false
+	}
+	return enabled == 1
+}
+
+// BulkLoad loads a delimited file with LOAD DATA LOCAL INFILE.
+// Fields equal to req.NullString, and empty fields flagged in
+// req.EmptyNull, are loaded as NULL. With req.Clear the existing rows are
+// deleted in the same transaction.
+func (c *Connector) BulkLoad(ctx context.Context, req db.BulkLoadRequest) (int64, error) {
+	mysql.RegisterLocalFile(req.Path)
+	defer mysql.DeregisterLocalFile(req.Path)
+
+	d := NewDialect()
+	var fields []string
+	var sets []string
+	for i, col := range req.Columns {
+		variable := fmt.Sprintf("@f%d", i)
+		fields = append(fields, variable)
+		if col == "" {
+			continue
+		}
+		value := fmt.Sprintf("NULLIF(%s, %s)", variable, quoteString(req.NullString))
+		if i < len(req.EmptyNull) && req.EmptyNull[i] {
+			value = fmt.Sprintf("NULLIF(%s, '')", value)
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s", d.QuoteIdentifier(col), value))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("LOAD DATA LOCAL INFILE %s INTO TABLE %s", quoteString(req.Path), db.QuoteQualifiedName(d, req.Table)))
+	sb.WriteString(fmt.Sprintf(" FIELDS TERMINATED BY %s", quoteString(string(req.Delimiter))))
+	if req.Delimiter == ',' {
+		sb.WriteString(` OPTIONALLY ENCLOSED BY '"' ESCAPED BY ''`)
+	}
+	terminator := req.LineTerminator
+	if terminator == "" {
+		terminator = "\n"
+	}
+	sb.WriteString(" LINES TERMINATED BY " + quoteString(terminator))
+	if req.SkipLines > 0 {
+		sb.WriteString(fmt.Sprintf(" IGNORE %d LINES", req.SkipLines))
+	}
+	sb.WriteString(fmt.Sprintf(" (%s)", strings.Join(fields, ", ")))
+	if len(sets) > 0 {
+		sb.WriteString(" SET ")
+		sb.WriteString(strings.Join(sets, ", "))
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if req.Clear {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+db.QuoteQualifiedName(d, req.Table)); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.ExecContext(ctx, sb.String())
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// quoteString returns s as a MySQL string literal.
+func quoteString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\x00", `\0`)
+	return "'" + r.Replace(s) + "'"
+}
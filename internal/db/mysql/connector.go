@@ -80,50 +80,7 @@ func (c *Connector) Execute(ctx context.Context, query string) (*db.QueryResult,
 }
 
 func (c *Connector) executeQuery(ctx context.Context, query string, start time.Time) (*db.QueryResult, error) {
-	rows, err := c.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
-
-	var resultRows [][]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		row := make([]interface{}, len(columns))
-		for i, v := range values {
-			if b, ok := v.([]byte); ok {
-				row[i] = string(b)
-			} else {
-				row[i] = v
-			}
-		}
-		resultRows = append(resultRows, row)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return &db.QueryResult{
-		Columns:  columns,
-		Rows:     resultRows,
-		IsSelect: true,
-		Duration: time.Since(start),
-	}, nil
+	return queryWithParams(ctx, c.db, query, nil, start)
 }
 
 func (c *Connector) executeExec(ctx context.Context, query string, start time.Time) (*db.QueryResult, error) {
@@ -157,21 +114,24 @@ func (c *Connector) GetCurrentDatabase(ctx context.Context) (string, error) {
 func (c *Connector) ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*db.QueryResult, error) {
 	start := time.Now()
 
-	trimmed := strings.TrimSpace(strings.ToUpper(query))
-	isSelect := strings.HasPrefix(trimmed, "SELECT") ||
-		strings.HasPrefix(trimmed, "SHOW") ||
-		strings.HasPrefix(trimmed, "DESCRIBE") ||
-		strings.HasPrefix(trimmed, "DESC") ||
-		strings.HasPrefix(trimmed, "EXPLAIN")
-
-	if isSelect {
+	if isSelectQuery(query) {
 		return c.executeQueryWithParams(ctx, query, args, start)
 	}
 	return c.executeExecWithParams(ctx, query, args, start)
 }
 
+// queryer is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 func (c *Connector) executeQueryWithParams(ctx context.Context, query string, args []interface{}, start time.Time) (*db.QueryResult, error) {
-	rows, err := c.db.QueryContext(ctx, query, args...)
+	return queryWithParams(ctx, c.db, query, args, start)
+}
+
+func queryWithParams(ctx context.Context, q queryer, query string, args []interface{}, start time.Time) (*db.QueryResult, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -181,6 +141,14 @@ func (c *Connector) executeQueryWithParams(ctx context.Context, query string, ar
 	if err != nil {
 		return nil, err
 	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	typeNames := make([]string, len(types))
+	for i, t := range types {
+		typeNames[i] = t.DatabaseTypeName()
+	}
 
 	var resultRows [][]interface{}
 	for rows.Next() {
@@ -210,15 +178,20 @@ func (c *Connector) executeQueryWithParams(ctx context.Context, query string, ar
 	}
 
 	return &db.QueryResult{
-		Columns:  columns,
-		Rows:     resultRows,
-		IsSelect: true,
-		Duration: time.Since(start),
+		Columns:     columns,
+		ColumnTypes: typeNames,
+		Rows:        resultRows,
+		IsSelect:    true,
+		Duration:    time.Since(start),
 	}, nil
 }
 
 func (c *Connector) executeExecWithParams(ctx context.Context, query string, args []interface{}, start time.Time) (*db.QueryResult, error) {
-	result, err := c.db.ExecContext(ctx, query, args...)
+	return execWithParams(ctx, c.db, query, args, start)
+}
+
+func execWithParams(ctx context.Context, q queryer, query string, args []interface{}, start time.Time) (*db.QueryResult, error) {
+	result, err := q.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -233,3 +206,70 @@ func (c *Connector) executeExecWithParams(ctx context.Context, query string, arg
 		Duration:     time.Since(start),
 	}, nil
 }
+
+// Begin starts a transaction on the underlying connection pool.
+func (c *Connector) Begin(ctx context.Context) (db.Transaction, error) {
+	if c.db == nil {
+		return nil, sql.ErrConnDone
+	}
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx}, nil
+}
+
+func isSelectQuery(query string) bool {
+	trimmed := strings.TrimSpace(strings.ToUpper(query))
+	return strings.HasPrefix(trimmed, "SELECT") ||
+		strings.HasPrefix(trimmed, "SHOW") ||
+		strings.HasPrefix(trimmed, "DESCRIBE") ||
+		strings.HasPrefix(trimmed, "DESC") ||
+		strings.HasPrefix(trimmed, "EXPLAIN")
+}
+
+// Stream runs a query and passes each row to w without buffering the result.
+func (c *Connector) Stream(ctx context.Context, query string, args []interface{}, w db.RowWriter) error {
+	return streamRows(ctx, c.db, query, args, w)
+}
+
+func streamRows(ctx context.Context, q queryer, query string, args []interface{}, w db.RowWriter) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err := w.WriteHeader(columns); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	row := make([]interface{}, len(columns))
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				row[i] = string(b)
+			} else {
+				row[i] = v
+			}
+		}
+		if err := w.WriteRow(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+func TestQueriesFillColumnTypes(t *testing.T) {
+	database := openRecorder(t)
+	defer database.Close()
+	c := &Connector{db: database}
+	ctx := context.Background()
+
+	queries := map[string]func() (*db.QueryResult, error){
+		"Execute": func() (*db.QueryResult, error) {
+			return c.Execute(ctx, "SELECT * FROM t")
+		},
+		"ExecuteWithParams": func() (*db.QueryResult, error) {
+			return c.ExecuteWithParams(ctx, "SELECT * FROM t WHERE id = ?", []interface{}{1})
+		},
+	}
+	for name, query := range queries {
+		result, err := query()
+		if err != nil {
+			t.Fatalf("%s() error: %v", name, err)
+		}
+		if len(result.ColumnTypes) != len(result.Columns) || result.ColumnTypes[0] != "INT" {
+			t.Errorf("%s() ColumnTypes = %v, want INT for each of %d columns", name, result.ColumnTypes, len(result.Columns))
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package mysql
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
 
 // Dialect implements db.Dialect for MySQL.
 type Dialect struct {
@@ -20,8 +25,7 @@ func NewDialect() *Dialect {
 			"ADD", "COLUMN", "PRIMARY", "KEY", "FOREIGN", "REFERENCES",
 			"UNIQUE", "CHECK", "DEFAULT", "NULL", "NOT", "AUTO_INCREMENT",
 			"IF", "EXISTS", "CASE", "WHEN", "THEN", "ELSE", "END",
-			"COUNT", "SUM", "AVG", "MIN", "MAX", "COALESCE", "NULLIF",
-			"CAST", "CONVERT", "DATE", "TIME", "DATETIME", "TIMESTAMP",
+			"CAST", "CONVERT", "OVER", "PARTITION", "DATE", "TIME", "DATETIME", "TIMESTAMP",
 			"YEAR", "MONTH", "DAY", "HOUR", "MINUTE", "SECOND",
 			"TRUE", "FALSE", "BETWEEN", "IS", "ESCAPE",
 			"SHOW", "TABLES", "DATABASES", "COLUMNS", "DESCRIBE", "EXPLAIN",
@@ -31,8 +35,6 @@ func NewDialect() *Dialect {
 			"FLOAT", "DOUBLE", "DECIMAL", "NUMERIC",
 			"VARCHAR", "CHAR", "TEXT", "BLOB", "BINARY", "VARBINARY",
 			"BOOLEAN", "BOOL", "ENUM", "JSON",
-			"JSON_CONTAINS", "JSON_CONTAINS_PATH", "JSON_EXTRACT",
-			"JSON_KEYS", "JSON_OVERLAPS", "JSON_SEARCH", "JSON_VALUE",
 		},
 	}
 }
@@ -49,31 +51,222 @@ func (d *Dialect) Keywords() []string {
 
 // QuoteIdentifier quotes an identifier for MySQL.
 func (d *Dialect) QuoteIdentifier(name string) string {
-	return "`" + name + "`"
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// schemaArg returns the placeholder argument for a schema name. An empty
+// schema becomes NULL so that COALESCE(?, DATABASE()) selects the current
+// database.
+func schemaArg(schema string) interface{} {
+	if schema == "" {
+		return nil
+	}
+	return schema
 }
 
-// GetTablesQuery returns the query to list tables.
-func (d *Dialect) GetTablesQuery() string {
-	return "SHOW TABLES"
+// GetTablesQuery returns the query to list tables of a schema.
+func (d *Dialect) GetTablesQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT TABLE_NAME
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY TABLE_NAME
+	`, []interface{}{schemaArg(schema)}
 }
 
 // GetColumnsQuery returns the query to get column information for a table.
-func (d *Dialect) GetColumnsQuery(tableName string) string {
-	return fmt.Sprintf(`
+func (d *Dialect) GetColumnsQuery(schema, tableName string) (string, []interface{}) {
+	return `
 		SELECT
 			COLUMN_NAME,
 			DATA_TYPE,
 			IS_NULLABLE,
 			COLUMN_KEY,
-			COLUMN_DEFAULT
+			COLUMN_DEFAULT,
+			COLUMN_TYPE,
+			EXTRA,
+			COLUMN_COMMENT,
+			CHARACTER_SET_NAME,
+			COLLATION_NAME
 		FROM INFORMATION_SCHEMA.COLUMNS
-		WHERE TABLE_NAME = '%s'
-		AND TABLE_SCHEMA = DATABASE()
+		WHERE TABLE_NAME = ?
+		AND TABLE_SCHEMA = COALESCE(?, DATABASE())
 		ORDER BY ORDINAL_POSITION
-	`, tableName)
+	`, []interface{}{tableName, schemaArg(schema)}
+}
+
+// GetAllColumnsQuery returns the query to get column information for
+// every table of a schema, ordered by table.
+func (d *Dialect) GetAllColumnsQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT
+			TABLE_NAME,
+			COLUMN_NAME,
+			DATA_TYPE,
+			IS_NULLABLE,
+			COLUMN_KEY,
+			COLUMN_DEFAULT,
+			COLUMN_TYPE,
+			EXTRA,
+			COLUMN_COMMENT,
+			CHARACTER_SET_NAME,
+			COLLATION_NAME
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY TABLE_NAME, ORDINAL_POSITION
+	`, []interface{}{schemaArg(schema)}
+}
+
+// GetSchemaChecksumQuery returns the query to compute a checksum that
+// changes whenever a table or column of the schema is added, dropped or
+// altered.
+func (d *Dialect) GetSchemaChecksumQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT CONCAT_WS(':',
+			COALESCE(?, DATABASE()),
+			COUNT(*),
+			COALESCE(SUM(CRC32(CONCAT_WS(0x1f,
+				TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE,
+				COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT))), 0))
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+	`, []interface{}{schemaArg(schema), schemaArg(schema)}
 }
 
 // GetDatabasesQuery returns the query to list databases.
 func (d *Dialect) GetDatabasesQuery() string {
 	return "SHOW DATABASES"
 }
+
+// GetIndexesQuery returns the query to get index information for a table.
+func (d *Dialect) GetIndexesQuery(schema, tableName string) (string, []interface{}) {
+	return `
+		SELECT
+			INDEX_NAME,
+			NON_UNIQUE,
+			COLUMN_NAME,
+			INDEX_TYPE
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_NAME = ?
+		AND TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX
+	`, []interface{}{tableName, schemaArg(schema)}
+}
+
+// GetForeignKeysQuery returns the query to get foreign keys of a table
+// and foreign keys referencing it.
+func (d *Dialect) GetForeignKeysQuery(schema, tableName string) (string, []interface{}) {
+	return `
+		SELECT
+			kcu.CONSTRAINT_NAME,
+			kcu.TABLE_NAME,
+			kcu.COLUMN_NAME,
+			kcu.REFERENCED_TABLE_NAME,
+			kcu.REFERENCED_COLUMN_NAME,
+			rc.UPDATE_RULE,
+			rc.DELETE_RULE
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+		JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+			ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA
+			AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+			AND rc.TABLE_NAME = kcu.TABLE_NAME
+		WHERE (kcu.TABLE_SCHEMA = COALESCE(?, DATABASE()) AND kcu.TABLE_NAME = ?)
+		OR (kcu.REFERENCED_TABLE_SCHEMA = COALESCE(?, DATABASE()) AND kcu.REFERENCED_TABLE_NAME = ?)
+		ORDER BY kcu.TABLE_NAME, kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION
+	`, []interface{}{schemaArg(schema), tableName, schemaArg(schema), tableName}
+}
+
+// GetViewsQuery returns the query to list views of a schema.
+func (d *Dialect) GetViewsQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT TABLE_NAME
+		FROM INFORMATION_SCHEMA.VIEWS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY TABLE_NAME
+	`, []interface{}{schemaArg(schema)}
+}
+
+// GetRoutinesQuery returns the query to list stored procedures and
+// functions of a schema.
+func (d *Dialect) GetRoutinesQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT
+			ROUTINE_NAME,
+			ROUTINE_TYPE,
+			DTD_IDENTIFIER,
+			ROUTINE_COMMENT
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE ROUTINE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY ROUTINE_TYPE, ROUTINE_NAME
+	`, []interface{}{schemaArg(schema)}
+}
+
+// GetTriggersQuery returns the query to list triggers of a schema.
+func (d *Dialect) GetTriggersQuery(schema string) (string, []interface{}) {
+	return `
+		SELECT
+			TRIGGER_NAME,
+			EVENT_OBJECT_TABLE,
+			ACTION_TIMING,
+			EVENT_MANIPULATION
+		FROM INFORMATION_SCHEMA.TRIGGERS
+		WHERE TRIGGER_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY EVENT_OBJECT_TABLE, TRIGGER_NAME
+	`, []interface{}{schemaArg(schema)}
+}
+
+// GetTableStatusQuery returns the query to get storage statistics of a table.
+func (d *Dialect) GetTableStatusQuery(schema, tableName string) (string, []interface{}) {
+	return `
+		SELECT
+			TABLE_NAME,
+			ENGINE,
+			TABLE_ROWS,
+			DATA_LENGTH,
+			INDEX_LENGTH,
+			TABLE_COLLATION,
+			TABLE_COMMENT
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_NAME = ?
+		AND TABLE_SCHEMA = COALESCE(?, DATABASE())
+	`, []interface{}{tableName, schemaArg(schema)}
+}
+
+// GetObjectTypeQuery returns the query to find the kind of a named object.
+func (d *Dialect) GetObjectTypeQuery(schema, name string) (string, []interface{}) {
+	s := schemaArg(schema)
+	return `
+		SELECT IF(TABLE_TYPE = 'VIEW', 'VIEW', 'TABLE')
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?
+		UNION ALL
+		SELECT ROUTINE_TYPE
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE ROUTINE_SCHEMA = COALESCE(?, DATABASE()) AND ROUTINE_NAME = ?
+		UNION ALL
+		SELECT 'TRIGGER'
+		FROM INFORMATION_SCHEMA.TRIGGERS
+		WHERE TRIGGER_SCHEMA = COALESCE(?, DATABASE()) AND TRIGGER_NAME = ?
+	`, []interface{}{s, name, s, name, s, name}
+}
+
+// GetDDLQuery returns the SHOW CREATE statement for an object. SHOW
+// statements do not accept placeholders, so the names are quoted instead.
+func (d *Dialect) GetDDLQuery(objectType db.ObjectType, schema, name string) string {
+	object := d.QuoteIdentifier(name)
+	if schema != "" {
+		object = d.QuoteIdentifier(schema) + "." + object
+	}
+	return fmt.Sprintf("SHOW CREATE %s %s", objectType, object)
+}
+
+// GetColumnValuesQuery returns a bounded query for distinct values of a
+// column. Identifiers cannot be placeholders, so they are quoted instead.
+func (d *Dialect) GetColumnValuesQuery(schema, tableName, column string, limit int) string {
+	table := d.QuoteIdentifier(tableName)
+	if schema != "" {
+		table = d.QuoteIdentifier(schema) + "." + table
+	}
+	col := d.QuoteIdentifier(column)
+	return fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL LIMIT %d", col, table, col, limit)
+}
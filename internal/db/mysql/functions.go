@@ -0,0 +1,167 @@
+package mysql
+
+import "github.com/mastar3104/sqcl/internal/db"
+
+// functions is the catalog of commonly used MySQL built-in functions.
+var functions = []db.FunctionInfo{
+	// Aggregate
+	{Name: "COUNT", Signature: "COUNT([DISTINCT] expr)", Returns: "BIGINT", Category: db.FunctionAggregate,
+		Description: "Number of non-NULL values of expr, or of rows for COUNT(*)"},
+	{Name: "SUM", Signature: "SUM([DISTINCT] expr)", Returns: "DECIMAL/DOUBLE", Category: db.FunctionAggregate,
+		Description: "Sum of expr; NULL when there are no rows"},
+	{Name: "AVG", Signature: "AVG([DISTINCT] expr)", Returns: "DECIMAL/DOUBLE", Category: db.FunctionAggregate,
+		Description: "Average value of expr"},
+	{Name: "MIN", Signature: "MIN([DISTINCT] expr)", Returns: "same as expr", Category: db.FunctionAggregate,
+		Description: "Minimum value of expr"},
+	{Name: "MAX", Signature: "MAX([DISTINCT] expr)", Returns: "same as expr", Category: db.FunctionAggregate,
+		Description: "Maximum value of expr"},
+	{Name: "GROUP_CONCAT", Signature: "GROUP_CONCAT([DISTINCT] expr [ORDER BY ...] [SEPARATOR str])", Returns: "TEXT",
+		Category: db.FunctionAggregate, Description: "Concatenation of the non-NULL values of a group"},
+	{Name: "JSON_ARRAYAGG", Signature: "JSON_ARRAYAGG(expr)", Returns: "JSON", Category: db.FunctionAggregate,
+		Description: "JSON array of the values of a group"},
+	{Name: "JSON_OBJECTAGG", Signature: "JSON_OBJECTAGG(key, value)", Returns: "JSON", Category: db.FunctionAggregate,
+		Description: "JSON object of the key/value pairs of a group"},
+	{Name: "STDDEV", Signature: "STDDEV(expr)", Returns: "DOUBLE", Category: db.FunctionAggregate,
+		Description: "Population standard deviation of expr"},
+	{Name: "BIT_OR", Signature: "BIT_OR(expr)", Returns: "BIGINT UNSIGNED", Category: db.FunctionAggregate,
+		Description: "Bitwise OR of all bits in expr"},
+
+	// Window
+	{Name: "ROW_NUMBER", Signature: "ROW_NUMBER() OVER (...)", Returns: "BIGINT", Category: db.FunctionWindow,
+		Description: "Number of the current row within its partition"},
+	{Name: "RANK", Signature: "RANK() OVER (...)", Returns: "BIGINT", Category: db.FunctionWindow,
+		Description: "Rank of the current row within its partition, with gaps"},
+	{Name: "DENSE_RANK", Signature: "DENSE_RANK() OVER (...)", Returns: "BIGINT", Category: db.FunctionWindow,
+		Description: "Rank of the current row within its partition, without gaps"},
+	{Name: "PERCENT_RANK", Signature: "PERCENT_RANK() OVER (...)", Returns: "DOUBLE", Category: db.FunctionWindow,
+		Description: "Relative rank of the current row: (rank - 1) / (rows - 1)"},
+	{Name: "NTILE", Signature: "NTILE(n) OVER (...)", Returns: "BIGINT", Category: db.FunctionWindow,
+		Description: "Number of the bucket, out of n, the current row falls into"},
+	{Name: "LAG", Signature: "LAG(expr[, n[, default]]) OVER (...)", Returns: "same as expr", Category: db.FunctionWindow,
+		Description: "Value of expr n rows before the current row"},
+	{Name: "LEAD", Signature: "LEAD(expr[, n[, default]]) OVER (...)", Returns: "same as expr", Category: db.FunctionWindow,
+		Description: "Value of expr n rows after the current row"},
+	{Name: "FIRST_VALUE", Signature: "FIRST_VALUE(expr) OVER (...)", Returns: "same as expr", Category: db.FunctionWindow,
+		Description: "Value of expr in the first row of the window frame"},
+	{Name: "LAST_VALUE", Signature: "LAST_VALUE(expr) OVER (...)", Returns: "same as expr", Category: db.FunctionWindow,
+		Description: "Value of expr in the last row of the window frame"},
+	{Name: "NTH_VALUE", Signature: "NTH_VALUE(expr, n) OVER (...)", Returns: "same as expr", Category: db.FunctionWindow,
+		Description: "Value of expr in the n-th row of the window frame"},
+
+	// String
+	{Name: "CONCAT", Signature: "CONCAT(str, str, ...)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Concatenation of the arguments; NULL if any argument is NULL"},
+	{Name: "CONCAT_WS", Signature: "CONCAT_WS(separator, str, str, ...)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Concatenation with a separator, skipping NULL arguments"},
+	{Name: "SUBSTRING", Signature: "SUBSTRING(str, pos[, len])", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Substring starting at pos (1-based, negative counts from the end)"},
+	{Name: "SUBSTRING_INDEX", Signature: "SUBSTRING_INDEX(str, delim, count)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Substring before count occurrences of delim (after, if count is negative)"},
+	{Name: "LENGTH", Signature: "LENGTH(str)", Returns: "INT", Category: db.FunctionString,
+		Description: "Length of str in bytes"},
+	{Name: "CHAR_LENGTH", Signature: "CHAR_LENGTH(str)", Returns: "INT", Category: db.FunctionString,
+		Description: "Length of str in characters"},
+	{Name: "LOWER", Signature: "LOWER(str)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "str converted to lower case"},
+	{Name: "UPPER", Signature: "UPPER(str)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "str converted to upper case"},
+	{Name: "TRIM", Signature: "TRIM([{BOTH | LEADING | TRAILING} [remstr] FROM] str)", Returns: "VARCHAR",
+		Category: db.FunctionString, Description: "str with leading and/or trailing remstr (spaces) removed"},
+	{Name: "REPLACE", Signature: "REPLACE(str, from_str, to_str)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "str with all occurrences of from_str replaced by to_str"},
+	{Name: "LPAD", Signature: "LPAD(str, len, padstr)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "str left-padded with padstr to len characters"},
+	{Name: "RPAD", Signature: "RPAD(str, len, padstr)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "str right-padded with padstr to len characters"},
+	{Name: "LOCATE", Signature: "LOCATE(substr, str[, pos])", Returns: "INT", Category: db.FunctionString,
+		Description: "Position of the first occurrence of substr in str, 0 if not found"},
+	{Name: "LEFT", Signature: "LEFT(str, len)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Leftmost len characters of str"},
+	{Name: "RIGHT", Signature: "RIGHT(str, len)", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "Rightmost len characters of str"},
+	{Name: "REGEXP_REPLACE", Signature: "REGEXP_REPLACE(expr, pat, repl[, pos[, occurrence[, match_type]]])",
+		Returns: "VARCHAR", Category: db.FunctionString, Description: "expr with matches of the regular expression pat replaced"},
+	{Name: "REGEXP_LIKE", Signature: "REGEXP_LIKE(expr, pat[, match_type])", Returns: "INT", Category: db.FunctionString,
+		Description: "1 if expr matches the regular expression pat, otherwise 0"},
+	{Name: "FORMAT", Signature: "FORMAT(x, d[, locale])", Returns: "VARCHAR", Category: db.FunctionString,
+		Description: "x formatted like '#,###,###.##' rounded to d decimals"},
+
+	// Date and time
+	{Name: "NOW", Signature: "NOW([fsp])", Returns: "DATETIME", Category: db.FunctionDate,
+		Description: "Current date and time at the start of the statement"},
+	{Name: "CURDATE", Signature: "CURDATE()", Returns: "DATE", Category: db.FunctionDate,
+		Description: "Current date"},
+	{Name: "CURTIME", Signature: "CURTIME([fsp])", Returns: "TIME", Category: db.FunctionDate,
+		Description: "Current time"},
+	{Name: "DATE_ADD", Signature: "DATE_ADD(date, INTERVAL expr unit)", Returns: "DATE/DATETIME", Category: db.FunctionDate,
+		Description: "date plus an interval"},
+	{Name: "DATE_SUB", Signature: "DATE_SUB(date, INTERVAL expr unit)", Returns: "DATE/DATETIME", Category: db.FunctionDate,
+		Description: "date minus an interval"},
+	{Name: "DATEDIFF", Signature: "DATEDIFF(expr1, expr2)", Returns: "INT", Category: db.FunctionDate,
+		Description: "Number of days from expr2 to expr1"},
+	{Name: "TIMESTAMPDIFF", Signature: "TIMESTAMPDIFF(unit, expr1, expr2)", Returns: "BIGINT", Category: db.FunctionDate,
+		Description: "expr2 - expr1 in the given unit"},
+	{Name: "DATE_FORMAT", Signature: "DATE_FORMAT(date, format)", Returns: "VARCHAR", Category: db.FunctionDate,
+		Description: "date formatted with %Y, %m, %d, %H, %i, %s, ..."},
+	{Name: "STR_TO_DATE", Signature: "STR_TO_DATE(str, format)", Returns: "DATE/DATETIME", Category: db.FunctionDate,
+		Description: "str parsed with a DATE_FORMAT format"},
+	{Name: "UNIX_TIMESTAMP", Signature: "UNIX_TIMESTAMP([date])", Returns: "BIGINT", Category: db.FunctionDate,
+		Description: "Seconds since 1970-01-01 00:00:00 UTC"},
+	{Name: "FROM_UNIXTIME", Signature: "FROM_UNIXTIME(unix_timestamp[, format])", Returns: "DATETIME", Category: db.FunctionDate,
+		Description: "Unix timestamp converted to a date and time"},
+	{Name: "LAST_DAY", Signature: "LAST_DAY(date)", Returns: "DATE", Category: db.FunctionDate,
+		Description: "Last day of the month of date"},
+	{Name: "EXTRACT", Signature: "EXTRACT(unit FROM date)", Returns: "INT", Category: db.FunctionDate,
+		Description: "Part of date, such as YEAR or MONTH"},
+	{Name: "CONVERT_TZ", Signature: "CONVERT_TZ(dt, from_tz, to_tz)", Returns: "DATETIME", Category: db.FunctionDate,
+		Description: "dt converted from one time zone to another"},
+
+	// JSON
+	{Name: "JSON_EXTRACT", Signature: "JSON_EXTRACT(json_doc, path[, path] ...)", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "Data at the paths of a JSON document (also json_doc->path)"},
+	{Name: "JSON_UNQUOTE", Signature: "JSON_UNQUOTE(json_val)", Returns: "LONGTEXT", Category: db.FunctionJSON,
+		Description: "JSON value unquoted as a string (also json_doc->>path)"},
+	{Name: "JSON_VALUE", Signature: "JSON_VALUE(json_doc, path [RETURNING type])", Returns: "VARCHAR or type",
+		Category: db.FunctionJSON, Description: "Scalar at path converted to type"},
+	{Name: "JSON_OBJECT", Signature: "JSON_OBJECT(key, val[, key, val] ...)", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "JSON object of the key/value pairs"},
+	{Name: "JSON_ARRAY", Signature: "JSON_ARRAY(val[, val] ...)", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "JSON array of the values"},
+	{Name: "JSON_CONTAINS", Signature: "JSON_CONTAINS(target, candidate[, path])", Returns: "INT", Category: db.FunctionJSON,
+		Description: "1 if candidate is contained in target (at path)"},
+	{Name: "JSON_CONTAINS_PATH", Signature: "JSON_CONTAINS_PATH(json_doc, 'one'|'all', path[, path] ...)", Returns: "INT",
+		Category: db.FunctionJSON, Description: "1 if the document contains data at one or all of the paths"},
+	{Name: "JSON_KEYS", Signature: "JSON_KEYS(json_doc[, path])", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "Keys of the top-level object (at path) as a JSON array"},
+	{Name: "JSON_OVERLAPS", Signature: "JSON_OVERLAPS(json_doc1, json_doc2)", Returns: "INT", Category: db.FunctionJSON,
+		Description: "1 if the documents share any key/value pair or array element"},
+	{Name: "JSON_SEARCH", Signature: "JSON_SEARCH(json_doc, 'one'|'all', search_str[, escape[, path] ...])", Returns: "JSON",
+		Category: db.FunctionJSON, Description: "Paths of the strings matching search_str"},
+	{Name: "JSON_SET", Signature: "JSON_SET(json_doc, path, val[, path, val] ...)", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "Document with values inserted or replaced at the paths"},
+	{Name: "JSON_REMOVE", Signature: "JSON_REMOVE(json_doc, path[, path] ...)", Returns: "JSON", Category: db.FunctionJSON,
+		Description: "Document with the data at the paths removed"},
+	{Name: "JSON_LENGTH", Signature: "JSON_LENGTH(json_doc[, path])", Returns: "INT", Category: db.FunctionJSON,
+		Description: "Number of elements of the document (at path)"},
+	{Name: "JSON_TABLE", Signature: "JSON_TABLE(expr, path COLUMNS (...)) [AS] alias", Returns: "table", Category: db.FunctionJSON,
+		Description: "Rows and columns extracted from a JSON document, used in FROM"},
+
+	// Control flow and conversion
+	{Name: "COALESCE", Signature: "COALESCE(value, ...)", Returns: "same as arguments", Category: db.FunctionControl,
+		Description: "First non-NULL argument"},
+	{Name: "IFNULL", Signature: "IFNULL(expr1, expr2)", Returns: "same as arguments", Category: db.FunctionControl,
+		Description: "expr1 unless it is NULL, otherwise expr2"},
+	{Name: "NULLIF", Signature: "NULLIF(expr1, expr2)", Returns: "same as expr1", Category: db.FunctionControl,
+		Description: "NULL if expr1 = expr2, otherwise expr1"},
+	{Name: "IF", Signature: "IF(expr1, expr2, expr3)", Returns: "same as expr2/expr3", Category: db.FunctionControl,
+		Description: "expr2 if expr1 is true, otherwise expr3"},
+	{Name: "CAST", Signature: "CAST(expr AS type)", Returns: "type", Category: db.FunctionControl,
+		Description: "expr converted to type, e.g. CHAR, SIGNED, DATE, JSON"},
+	{Name: "CONVERT", Signature: "CONVERT(expr, type) / CONVERT(expr USING charset)", Returns: "type", Category: db.FunctionControl,
+		Description: "expr converted to type or character set"},
+}
+
+// Functions returns the catalog of built-in functions.
+func (d *Dialect) Functions() []db.FunctionInfo {
+	return functions
+}
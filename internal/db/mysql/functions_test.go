@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+func TestFunctionsCatalog(t *testing.T) {
+	categories := map[db.FunctionCategory]bool{
+		db.FunctionString: false, db.FunctionDate: false, db.FunctionJSON: false,
+		db.FunctionWindow: false, db.FunctionAggregate: false, db.FunctionControl: false,
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range NewDialect().Functions() {
+		if seen[f.Name] {
+			t.Errorf("duplicate function %s", f.Name)
+		}
+		seen[f.Name] = true
+
+		if !strings.HasPrefix(f.Signature, f.Name+"(") {
+			t.Errorf("%s: signature %q does not start with the name", f.Name, f.Signature)
+		}
+		if f.Returns == "" || f.Description == "" {
+			t.Errorf("%s: missing return type or description", f.Name)
+		}
+		if _, ok := categories[f.Category]; !ok {
+			t.Errorf("%s: unknown category %q", f.Name, f.Category)
+		}
+		categories[f.Category] = true
+	}
+
+	for category, used := range categories {
+		if !used {
+			t.Errorf("no functions in category %s", category)
+		}
+	}
+}
@@ -3,10 +3,16 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/mastar3104/sqcl/internal/db"
 )
 
+// ErrObjectNotFound is returned when no object with the given name exists.
+var ErrObjectNotFound = errors.New("object not found")
+
 // MetadataProvider implements db.MetadataProvider for MySQL.
 type MetadataProvider struct {
 	database *sql.DB
@@ -21,9 +27,10 @@ func NewMetadataProvider(database *sql.DB) *MetadataProvider {
 	}
 }
 
-// GetTables returns a list of table names in the current database.
-func (m *MetadataProvider) GetTables(ctx context.Context) ([]string, error) {
-	rows, err := m.database.QueryContext(ctx, m.dialect.GetTablesQuery())
+// GetTables returns a list of table names in a schema.
+func (m *MetadataProvider) GetTables(ctx context.Context, schema string) ([]string, error) {
+	query, args := m.dialect.GetTablesQuery(schema)
+	rows, err := m.database.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -42,8 +49,9 @@ func (m *MetadataProvider) GetTables(ctx context.Context) ([]string, error) {
 }
 
 // GetColumns returns column information for a specific table.
-func (m *MetadataProvider) GetColumns(ctx context.Context, tableName string) ([]db.ColumnInfo, error) {
-	rows, err := m.database.QueryContext(ctx, m.dialect.GetColumnsQuery(tableName))
+func (m *MetadataProvider) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	query, args := m.dialect.GetColumnsQuery(schema, tableName)
+	rows, err := m.database.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -51,27 +59,72 @@ func (m *MetadataProvider) GetColumns(ctx context.Context, tableName string) ([]
 
 	var columns []db.ColumnInfo
 	for rows.Next() {
-		var col db.ColumnInfo
-		var isNullable string
-		var columnKey string
-		var defaultVal sql.NullString
-
-		if err := rows.Scan(&col.Name, &col.DataType, &isNullable, &columnKey, &defaultVal); err != nil {
+		col, err := scanColumn(rows)
+		if err != nil {
 			return nil, err
 		}
+		columns = append(columns, col)
+	}
 
-		col.IsNullable = isNullable == "YES"
-		col.IsPrimary = columnKey == "PRI"
-		if defaultVal.Valid {
-			col.Default = &defaultVal.String
-		}
+	return columns, rows.Err()
+}
 
-		columns = append(columns, col)
+// GetAllColumns returns column information for every table in a schema.
+func (m *MetadataProvider) GetAllColumns(ctx context.Context, schema string) (map[string][]db.ColumnInfo, error) {
+	query, args := m.dialect.GetAllColumnsQuery(schema)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]db.ColumnInfo)
+	for rows.Next() {
+		var tableName string
+		col, err := scanColumn(rows, &tableName)
+		if err != nil {
+			return nil, err
+		}
+		columns[tableName] = append(columns[tableName], col)
 	}
 
 	return columns, rows.Err()
 }
 
+// GetSchemaChecksum returns a checksum of the tables and columns of a schema.
+func (m *MetadataProvider) GetSchemaChecksum(ctx context.Context, schema string) (string, error) {
+	query, args := m.dialect.GetSchemaChecksumQuery(schema)
+	var checksum sql.NullString
+	if err := m.database.QueryRowContext(ctx, query, args...).Scan(&checksum); err != nil {
+		return "", err
+	}
+	return checksum.String, nil
+}
+
+// scanColumn scans a row of a columns query. Extra destinations for
+// leading columns, such as the table name, come first.
+func scanColumn(rows *sql.Rows, leading ...interface{}) (db.ColumnInfo, error) {
+	var col db.ColumnInfo
+	var isNullable string
+	var defaultVal sql.NullString
+	var charset, collation sql.NullString
+
+	dest := append(leading, &col.Name, &col.DataType, &isNullable, &col.Key, &defaultVal,
+		&col.FullType, &col.Extra, &col.Comment, &charset, &collation)
+	if err := rows.Scan(dest...); err != nil {
+		return col, err
+	}
+
+	col.IsNullable = isNullable == "YES"
+	col.IsPrimary = col.Key == "PRI"
+	if defaultVal.Valid {
+		col.Default = &defaultVal.String
+	}
+	col.Charset = charset.String
+	col.Collation = collation.String
+	return col, nil
+}
+
 // GetDatabases returns a list of database names.
 func (m *MetadataProvider) GetDatabases(ctx context.Context) ([]string, error) {
 	rows, err := m.database.QueryContext(ctx, m.dialect.GetDatabasesQuery())
@@ -91,3 +144,247 @@ func (m *MetadataProvider) GetDatabases(ctx context.Context) ([]string, error) {
 
 	return databases, rows.Err()
 }
+
+// GetIndexes returns index information for a specific table.
+func (m *MetadataProvider) GetIndexes(ctx context.Context, schema, tableName string) ([]db.IndexInfo, error) {
+	query, args := m.dialect.GetIndexesQuery(schema, tableName)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []db.IndexInfo
+	for rows.Next() {
+		var name, indexType string
+		var column sql.NullString
+		var nonUnique int
+		if err := rows.Scan(&name, &nonUnique, &column, &indexType); err != nil {
+			return nil, err
+		}
+		// Functional key parts have no column name
+		part := column.String
+		if !column.Valid {
+			part = "(expression)"
+		}
+
+		// Rows are ordered by index, one row per indexed column
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, part)
+			continue
+		}
+		indexes = append(indexes, db.IndexInfo{
+			Name:    name,
+			Unique:  nonUnique == 0,
+			Columns: []string{part},
+			Type:    indexType,
+		})
+	}
+
+	return indexes, rows.Err()
+}
+
+// GetForeignKeys returns foreign keys of a table and foreign keys referencing it.
+func (m *MetadataProvider) GetForeignKeys(ctx context.Context, schema, tableName string) ([]db.ForeignKeyInfo, error) {
+	query, args := m.dialect.GetForeignKeysQuery(schema, tableName)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []db.ForeignKeyInfo
+	for rows.Next() {
+		var name, table, column, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &table, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+
+		// Rows are ordered by constraint, one row per column pair
+		if n := len(keys); n > 0 && keys[n-1].Name == name && keys[n-1].Table == table {
+			keys[n-1].Columns = append(keys[n-1].Columns, column)
+			keys[n-1].RefColumns = append(keys[n-1].RefColumns, refColumn)
+			continue
+		}
+		keys = append(keys, db.ForeignKeyInfo{
+			Name:       name,
+			Table:      table,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+			OnUpdate:   onUpdate,
+			OnDelete:   onDelete,
+		})
+	}
+
+	return keys, rows.Err()
+}
+
+// GetViews returns a list of view names in a schema.
+func (m *MetadataProvider) GetViews(ctx context.Context, schema string) ([]string, error) {
+	query, args := m.dialect.GetViewsQuery(schema)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		views = append(views, name)
+	}
+
+	return views, rows.Err()
+}
+
+// GetRoutines returns stored procedures and functions in a schema.
+func (m *MetadataProvider) GetRoutines(ctx context.Context, schema string) ([]db.RoutineInfo, error) {
+	query, args := m.dialect.GetRoutinesQuery(schema)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routines []db.RoutineInfo
+	for rows.Next() {
+		var r db.RoutineInfo
+		var routineType string
+		var returns, comment sql.NullString
+		if err := rows.Scan(&r.Name, &routineType, &returns, &comment); err != nil {
+			return nil, err
+		}
+		r.Type = db.ObjectType(routineType)
+		r.Returns = returns.String
+		r.Comment = comment.String
+		routines = append(routines, r)
+	}
+
+	return routines, rows.Err()
+}
+
+// GetTriggers returns triggers in a schema.
+func (m *MetadataProvider) GetTriggers(ctx context.Context, schema string) ([]db.TriggerInfo, error) {
+	query, args := m.dialect.GetTriggersQuery(schema)
+	rows, err := m.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []db.TriggerInfo
+	for rows.Next() {
+		var t db.TriggerInfo
+		if err := rows.Scan(&t.Name, &t.Table, &t.Timing, &t.Event); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+
+	return triggers, rows.Err()
+}
+
+// GetTableStatus returns storage statistics for a table.
+func (m *MetadataProvider) GetTableStatus(ctx context.Context, schema, tableName string) (*db.TableStatus, error) {
+	var status db.TableStatus
+	var engine, collation sql.NullString
+	var rows, dataLength, indexLength sql.NullInt64
+
+	query, args := m.dialect.GetTableStatusQuery(schema, tableName)
+	err := m.database.QueryRowContext(ctx, query, args...).Scan(
+		&status.Name, &engine, &rows, &dataLength, &indexLength, &collation, &status.Comment)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, tableName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Views have no engine or statistics
+	status.Engine = engine.String
+	status.Rows = rows.Int64
+	status.DataLength = dataLength.Int64
+	status.IndexLength = indexLength.Int64
+	status.Collation = collation.String
+	return &status, nil
+}
+
+// GetDDL returns the SHOW CREATE output for an object.
+func (m *MetadataProvider) GetDDL(ctx context.Context, objectType db.ObjectType, schema, name string) (*db.ObjectDDL, error) {
+	if objectType == "" {
+		var kind string
+		query, args := m.dialect.GetObjectTypeQuery(schema, name)
+		err := m.database.QueryRowContext(ctx, query, args...).Scan(&kind)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		objectType = db.ObjectType(kind)
+	}
+
+	rows, err := m.database.QueryContext(ctx, m.dialect.GetDDLQuery(objectType, schema, name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, name)
+	}
+
+	values := make([]sql.NullString, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+
+	// "Create Table", "Create View", "Create Procedure", ... or
+	// "SQL Original Statement" for triggers
+	for i, col := range columns {
+		if strings.HasPrefix(col, "Create ") || col == "SQL Original Statement" {
+			if !values[i].Valid {
+				return nil, fmt.Errorf("definition of %s is not available (missing privileges?)", name)
+			}
+			return &db.ObjectDDL{Type: objectType, Name: name, SQL: values[i].String}, nil
+		}
+	}
+	return nil, fmt.Errorf("definition of %s is not available", name)
+}
+
+// GetColumnValues returns up to limit distinct non-NULL values of a column.
+func (m *MetadataProvider) GetColumnValues(ctx context.Context, schema, tableName, column string, limit int) ([]string, error) {
+	rows, err := m.database.QueryContext(ctx, m.dialect.GetColumnValuesQuery(schema, tableName, column, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			values = append(values, value.String)
+		}
+	}
+
+	return values, rows.Err()
+}
@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordingDriver is a database/sql driver that records the last query
+// and its arguments and returns no rows of INT columns.
+type recordingDriver struct {
+	mu    sync.Mutex
+	query string
+	args  []driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) last() (string, []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.query, d.args
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	c.driver.mu.Lock()
+	c.driver.query = query
+	c.driver.args = values
+	c.driver.mu.Unlock()
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return make([]string, 10) }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// ColumnTypeDatabaseTypeName reports every column as INT.
+func (emptyRows) ColumnTypeDatabaseTypeName(index int) string { return "INT" }
+
+var (
+	recorder     = &recordingDriver{}
+	registerOnce sync.Once
+)
+
+func openRecorder(t testing.TB) *sql.DB {
+	registerOnce.Do(func() { sql.Register("sqcl-recorder", recorder) })
+	database, err := sql.Open("sqcl-recorder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	return database
+}
+
+func FuzzGetColumns(f *testing.F) {
+	f.Add("", "users")
+	f.Add("shop", "orders")
+	f.Add("", "users' OR '1'='1")
+	f.Add("x'; DROP TABLE users; --", "t")
+	f.Add("", "a`b\\'c\x00")
+	f.Add("", "\"; SELECT SLEEP(10) /*")
+
+	database := openRecorder(f)
+	defer database.Close()
+	provider := NewMetadataProvider(database)
+	baseQuery, _ := provider.dialect.GetColumnsQuery("", "")
+
+	f.Fuzz(func(t *testing.T, schema, table string) {
+		if _, err := provider.GetColumns(context.Background(), schema, table); err != nil {
+			t.Fatalf("GetColumns(%q, %q) error: %v", schema, table, err)
+		}
+
+		query, args := recorder.last()
+		if query != baseQuery {
+			t.Fatalf("identifiers leaked into the query text:\n%s", query)
+		}
+		if len(args) != 2 || args[0] != table {
+			t.Fatalf("args = %#v, want table %q first", args, table)
+		}
+		if schema == "" && args[1] != nil {
+			t.Fatalf("empty schema passed as %#v, want NULL", args[1])
+		}
+		if schema != "" && args[1] != schema {
+			t.Fatalf("schema arg = %#v, want %q", args[1], schema)
+		}
+	})
+}
+
+func TestGetColumnValuesQuotesIdentifiers(t *testing.T) {
+	database := openRecorder(t)
+	defer database.Close()
+	provider := NewMetadataProvider(database)
+
+	if _, err := provider.GetColumnValues(context.Background(), "shop", "or`ders", "status", 50); err != nil {
+		t.Fatal(err)
+	}
+	query, _ := recorder.last()
+	want := "SELECT DISTINCT `status` FROM `shop`.`or``ders` WHERE `status` IS NOT NULL LIMIT 50"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestGetDDLUsesSchema(t *testing.T) {
+	database := openRecorder(t)
+	defer database.Close()
+	provider := NewMetadataProvider(database)
+
+	_, err := provider.GetDDL(context.Background(), "TABLE", "shop", "orders")
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("GetDDL() error = %v, want ErrObjectNotFound", err)
+	}
+	if query, _ := recorder.last(); query != "SHOW CREATE TABLE `shop`.`orders`" {
+		t.Errorf("query = %q", query)
+	}
+
+	_, err = provider.GetDDL(context.Background(), "", "shop", "orders")
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("GetDDL() error = %v, want ErrObjectNotFound", err)
+	}
+	_, args := recorder.last()
+	want := []driver.Value{"shop", "orders", "shop", "orders", "shop", "orders"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("object type args = %#v, want %#v", args, want)
+	}
+}
+
+func TestObjectListsUseSchema(t *testing.T) {
+	database := openRecorder(t)
+	defer database.Close()
+	provider := NewMetadataProvider(database)
+	ctx := context.Background()
+
+	lists := map[string]func(schema string) error{
+		"GetViews": func(schema string) error {
+			_, err := provider.GetViews(ctx, schema)
+			return err
+		},
+		"GetRoutines": func(schema string) error {
+			_, err := provider.GetRoutines(ctx, schema)
+			return err
+		},
+		"GetTriggers": func(schema string) error {
+			_, err := provider.GetTriggers(ctx, schema)
+			return err
+		},
+	}
+	for name, list := range lists {
+		for _, schema := range []string{"", "shop"} {
+			if err := list(schema); err != nil {
+				t.Fatalf("%s(%q) error: %v", name, schema, err)
+			}
+			var want driver.Value
+			if schema != "" {
+				want = schema
+			}
+			if _, args := recorder.last(); len(args) != 1 || args[0] != want {
+				t.Errorf("%s(%q) args = %#v, want [%#v]", name, schema, args, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// Snapshot implements db.Snapshot with a REPEATABLE READ transaction
+// started WITH CONSISTENT SNAPSHOT on a dedicated connection.
+type Snapshot struct {
+	conn *sql.Conn
+}
+
+// BeginSnapshot starts a consistent snapshot. database/sql cannot start a
+// transaction WITH CONSISTENT SNAPSHOT, so it is issued on a dedicated
+// connection instead.
+func (c *Connector) BeginSnapshot(ctx context.Context) (db.Snapshot, error) {
+	if c.db == nil {
+		return nil, sql.ErrConnDone
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range []string{
+		"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+	} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return &Snapshot{conn: conn}, nil
+}
+
+// Stream runs a query inside the snapshot.
+func (s *Snapshot) Stream(ctx context.Context, query string, args []interface{}, w db.RowWriter) error {
+	return streamRows(ctx, s.conn, query, args, w)
+}
+
+// Close ends the snapshot and returns the connection to the pool.
+func (s *Snapshot) Close() error {
+	_, err := s.conn.ExecContext(context.Background(), "COMMIT")
+	if cerr := s.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// MySQL errors after which InnoDB may have rolled back the transaction.
+// A lock wait timeout does so when innodb_rollback_on_timeout is set.
+const (
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+)
+
+// Tx implements db.Transaction for MySQL.
+type Tx struct {
+	tx *sql.Tx
+}
+
+// ExecuteWithParams runs a SQL query with parameters inside the transaction.
+func (t *Tx) ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*db.QueryResult, error) {
+	start := time.Now()
+
+	var result *db.QueryResult
+	var err error
+	if isSelectQuery(query) {
+		result, err = queryWithParams(ctx, t.tx, query, args, start)
+	} else {
+		result, err = execWithParams(ctx, t.tx, query, args, start)
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && (mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout) {
+		err = fmt.Errorf("%w: %w", db.ErrTransactionAborted, err)
+	}
+	return result, err
+}
+
+// Commit commits the transaction.
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -1,10 +1,16 @@
 package db
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // QueryResult holds the result of a SQL query execution.
 type QueryResult struct {
-	Columns      []string
+	Columns []string
+	// ColumnTypes are the database type names of the columns, e.g. INT or
+	// VARCHAR, when the driver reports them.
+	ColumnTypes  []string
 	Rows         [][]interface{}
 	RowsAffected int64
 	LastInsertID int64
@@ -12,6 +18,17 @@ type QueryResult struct {
 	Duration     time.Duration
 }
 
+// IsNumericType reports whether a database type name, as in
+// QueryResult.ColumnTypes, is an integer or decimal type.
+func IsNumericType(typeName string) bool {
+	t := strings.TrimPrefix(strings.ToUpper(typeName), "UNSIGNED ")
+	switch t {
+	case "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL":
+		return true
+	}
+	return strings.HasSuffix(t, "INT")
+}
+
 // ColumnInfo holds metadata about a table column.
 type ColumnInfo struct {
 	Name       string
@@ -19,6 +36,114 @@ type ColumnInfo struct {
 	IsNullable bool
 	IsPrimary  bool
 	Default    *string
+	// FullType is the complete column type, e.g. varchar(255) or int unsigned.
+	FullType  string
+	Key       string
+	Extra     string
+	Comment   string
+	Charset   string
+	Collation string
+}
+
+// IsAutoIncrement reports whether the column is AUTO_INCREMENT.
+func (c ColumnInfo) IsAutoIncrement() bool {
+	return strings.Contains(strings.ToLower(c.Extra), "auto_increment")
+}
+
+// IsGenerated reports whether the column is a generated column.
+func (c ColumnInfo) IsGenerated() bool {
+	extra := strings.ToUpper(c.Extra)
+	return strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED")
+}
+
+// EnumValues returns the allowed values of an ENUM or SET column, parsed
+// from its full type, e.g. enum('draft','paid'). It returns nil for other
+// types.
+func (c ColumnInfo) EnumValues() []string {
+	lower := strings.ToLower(c.FullType)
+	var body string
+	switch {
+	case strings.HasPrefix(lower, "enum(") && strings.HasSuffix(lower, ")"):
+		body = c.FullType[len("enum(") : len(c.FullType)-1]
+	case strings.HasPrefix(lower, "set(") && strings.HasSuffix(lower, ")"):
+		body = c.FullType[len("set(") : len(c.FullType)-1]
+	default:
+		return nil
+	}
+
+	var values []string
+	var value strings.Builder
+	quoted := false
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case !quoted:
+			if ch == '\'' {
+				quoted = true
+				value.Reset()
+			}
+		case ch == '\\' && i+1 < len(body):
+			i++
+			value.WriteByte(body[i])
+		case ch == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			// A doubled quote stands for one quote
+			i++
+			value.WriteByte(ch)
+		case ch == '\'':
+			quoted = false
+			values = append(values, value.String())
+		default:
+			value.WriteByte(ch)
+		}
+	}
+	return values
+}
+
+// IndexInfo holds metadata about a table index.
+type IndexInfo struct {
+	Name    string
+	Unique  bool
+	Columns []string
+	Type    string
+}
+
+// ForeignKeyInfo holds metadata about a foreign key constraint.
+type ForeignKeyInfo struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// RoutineInfo holds metadata about a stored procedure or function.
+type RoutineInfo struct {
+	Name    string
+	Type    ObjectType
+	Returns string
+	Comment string
+}
+
+// TriggerInfo holds metadata about a trigger.
+type TriggerInfo struct {
+	Name   string
+	Table  string
+	Timing string
+	Event  string
+}
+
+// TableStatus holds storage statistics of a table. Row counts and sizes
+// are estimates reported by the storage engine.
+type TableStatus struct {
+	Name        string
+	Engine      string
+	Rows        int64
+	DataLength  int64
+	IndexLength int64
+	Collation   string
+	Comment     string
 }
 
 // TableInfo holds metadata about a database table.
@@ -26,3 +151,127 @@ type TableInfo struct {
 	Name    string
 	Columns []ColumnInfo
 }
+
+// BulkLoadRequest describes a delimited file to load into a table.
+type BulkLoadRequest struct {
+	Path    string
+	Table   string
+	Columns []string // target column for each field, "" to discard the field
+	// EmptyNull is set for the fields whose empty value is loaded as NULL.
+	EmptyNull []bool
+	// NullString is the field value loaded as NULL.
+	NullString string
+	Delimiter  rune
+	// LineTerminator ends each line, "\n" or "\r\n".
+	LineTerminator string
+	SkipLines      int
+	// Clear deletes the rows of the table in the transaction of the load.
+	Clear bool
+}
+
+// ObjectType identifies the kind of a schema object.
+type ObjectType string
+
+const (
+	ObjectTable     ObjectType = "TABLE"
+	ObjectView      ObjectType = "VIEW"
+	ObjectProcedure ObjectType = "PROCEDURE"
+	ObjectFunction  ObjectType = "FUNCTION"
+	ObjectTrigger   ObjectType = "TRIGGER"
+)
+
+// ParseObjectType converts a name such as "table" or "proc" to an ObjectType.
+func ParseObjectType(name string) (ObjectType, bool) {
+	switch strings.ToUpper(name) {
+	case "TABLE":
+		return ObjectTable, true
+	case "VIEW":
+		return ObjectView, true
+	case "PROCEDURE", "PROC":
+		return ObjectProcedure, true
+	case "FUNCTION", "FUNC":
+		return ObjectFunction, true
+	case "TRIGGER":
+		return ObjectTrigger, true
+	}
+	return "", false
+}
+
+// ObjectDDL holds the definition of a schema object.
+type ObjectDDL struct {
+	Type ObjectType
+	Name string
+	SQL  string
+}
+
+// SplitQualifiedName splits a possibly schema-qualified name such as
+// "shop.orders" or "`my.db`.`orders`" into its schema and table parts.
+// The schema is empty when the name is not qualified.
+func SplitQualifiedName(name string) (schema, table string) {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+	runes := []rune(strings.TrimSpace(name))
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '`' && quoted && i+1 < len(runes) && runes[i+1] == '`':
+			current.WriteRune('`')
+			i++
+		case ch == '`':
+			quoted = !quoted
+		case ch == '.' && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	parts = append(parts, current.String())
+
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// QuoteQualifiedName quotes the schema and name parts of a possibly
+// qualified name separately, e.g. "shop.orders" as `shop`.`orders`.
+func QuoteQualifiedName(d Dialect, name string) string {
+	schema, table := SplitQualifiedName(name)
+	if schema == "" {
+		return d.QuoteIdentifier(table)
+	}
+	return d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(table)
+}
+
+// FunctionCategory groups built-in functions.
+type FunctionCategory string
+
+const (
+	FunctionString    FunctionCategory = "string"
+	FunctionDate      FunctionCategory = "date"
+	FunctionJSON      FunctionCategory = "json"
+	FunctionWindow    FunctionCategory = "window"
+	FunctionAggregate FunctionCategory = "aggregate"
+	FunctionControl   FunctionCategory = "control"
+)
+
+// FunctionInfo documents a built-in function.
+type FunctionInfo struct {
+	Name        string
+	Signature   string // e.g. SUBSTRING(str, pos[, len])
+	Returns     string
+	Category    FunctionCategory
+	Description string
+}
+
+// FindFunction looks up a function by name, ignoring case.
+func FindFunction(functions []FunctionInfo, name string) (FunctionInfo, bool) {
+	for _, f := range functions {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return FunctionInfo{}, false
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestSplitQualifiedName(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantSchema string
+		wantTable  string
+	}{
+		{
+			name:      "plain table",
+			input:     "orders",
+			wantTable: "orders",
+		},
+		{
+			name:       "qualified table",
+			input:      "shop.orders",
+			wantSchema: "shop",
+			wantTable:  "orders",
+		},
+		{
+			name:       "backquoted with dot inside",
+			input:      "`my.db`.`orders`",
+			wantSchema: "my.db",
+			wantTable:  "orders",
+		},
+		{
+			name:      "escaped backquote",
+			input:     "`a``b`",
+			wantTable: "a`b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, table := SplitQualifiedName(tt.input)
+			if schema != tt.wantSchema || table != tt.wantTable {
+				t.Errorf("SplitQualifiedName(%q) = (%q, %q), want (%q, %q)",
+					tt.input, schema, table, tt.wantSchema, tt.wantTable)
+			}
+		})
+	}
+}
+
+func TestFindFunction(t *testing.T) {
+	functions := []FunctionInfo{{Name: "COUNT"}, {Name: "JSON_EXTRACT"}}
+
+	if f, ok := FindFunction(functions, "json_extract"); !ok || f.Name != "JSON_EXTRACT" {
+		t.Errorf("FindFunction(json_extract) = %v, %v", f, ok)
+	}
+	if _, ok := FindFunction(functions, "SUM"); ok {
+		t.Error("FindFunction(SUM) found a missing function")
+	}
+}
+
+func TestEnumValues(t *testing.T) {
+	tests := []struct {
+		fullType string
+		want     []string
+	}{
+		{"enum('draft','paid','shipped')", []string{"draft", "paid", "shipped"}},
+		{"set('a','b')", []string{"a", "b"}},
+		{"ENUM('it''s','a,b')", []string{"it's", "a,b"}},
+		{"enum('back\\\\slash','')", []string{"back\\slash", ""}},
+		{"varchar(255)", nil},
+	}
+
+	for _, tt := range tests {
+		got := ColumnInfo{FullType: tt.fullType}.EnumValues()
+		if len(got) != len(tt.want) {
+			t.Errorf("EnumValues(%q) = %q, want %q", tt.fullType, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("EnumValues(%q) = %q, want %q", tt.fullType, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// definerPattern matches DEFINER clauses, which reference accounts that
+// usually do not exist where a fixture is restored.
+var definerPattern = regexp.MustCompile("DEFINER=(`[^`]*`|'[^']*'|[^ ]+)@(`[^`]*`|'[^']*'|[^ ]+) ")
+
+// Options holds configuration for a dump.
+type Options struct {
+	// Tables limits the dump to these tables and views. Empty dumps everything,
+	// including routines.
+	Tables []string
+	// NoData skips INSERT statements.
+	NoData bool
+	// Where filters the rows of every table.
+	Where string
+	// BatchSize is the number of rows per INSERT statement.
+	BatchSize int
+}
+
+// Run writes schema and data of the current database to w.
+func Run(ctx context.Context, conn db.Connector, metadata db.MetadataProvider, dialect db.Dialect, w io.Writer, opts Options) error {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	database, err := conn.GetCurrentDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	if database == "" {
+		return fmt.Errorf("no database selected")
+	}
+
+	tables, views, err := listTables(ctx, metadata)
+	if err != nil {
+		return err
+	}
+	if len(opts.Tables) > 0 {
+		tables, views, err = filterTables(tables, views, opts.Tables)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read every table from one snapshot so that the data is consistent
+	var source streamer = conn
+	if reader, ok := conn.(db.SnapshotReader); ok && !opts.NoData {
+		snapshot, err := reader.BeginSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		defer snapshot.Close()
+		source = snapshot
+	}
+
+	fmt.Fprintf(out, "-- sqcl dump\n-- Database: %s\n-- Date: %s\n\n", database, time.Now().Format(time.RFC3339))
+	out.WriteString("SET NAMES utf8mb4;\nSET FOREIGN_KEY_CHECKS = 0;\n\n")
+
+	for _, table := range tables {
+		ddl, err := metadata.GetDDL(ctx, db.ObjectTable, "", table)
+		if err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		fmt.Fprintf(out, "--\n-- Table structure for %s\n--\n\n", table)
+		fmt.Fprintf(out, "DROP TABLE IF EXISTS %s;\n%s;\n\n", dialect.QuoteIdentifier(table), ddl.SQL)
+
+		if opts.NoData {
+			continue
+		}
+		if err := dumpData(ctx, source, metadata, dialect, out, table, opts); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+
+	// Views come after tables since they depend on them
+	if err := dumpViews(ctx, metadata, dialect, out, views); err != nil {
+		return err
+	}
+
+	if err := dumpTriggers(ctx, metadata, dialect, out, tables); err != nil {
+		return err
+	}
+
+	if len(opts.Tables) == 0 {
+		if err := dumpRoutines(ctx, metadata, dialect, out); err != nil {
+			return err
+		}
+	}
+
+	out.WriteString("SET FOREIGN_KEY_CHECKS = 1;\n")
+	return nil
+}
+
+func listTables(ctx context.Context, metadata db.MetadataProvider) ([]string, []string, error) {
+	all, err := metadata.GetTables(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	views, err := metadata.GetViews(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isView := make(map[string]bool, len(views))
+	for _, v := range views {
+		isView[v] = true
+	}
+	var tables []string
+	for _, t := range all {
+		if !isView[t] {
+			tables = append(tables, t)
+		}
+	}
+	return tables, views, nil
+}
+
+func filterTables(tables, views, names []string) ([]string, []string, error) {
+	isView := make(map[string]bool)
+	for _, t := range tables {
+		isView[strings.ToLower(t)] = false
+	}
+	for _, v := range views {
+		isView[strings.ToLower(v)] = true
+	}
+
+	var selTables, selViews []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		view, ok := isView[strings.ToLower(name)]
+		if !ok {
+			return nil, nil, fmt.Errorf("table not found: %s", name)
+		}
+		if view {
+			selViews = append(selViews, name)
+		} else {
+			selTables = append(selTables, name)
+		}
+	}
+	return selTables, selViews, nil
+}
+
+// streamer runs a query and streams its rows, either on the connection
+// pool or inside a snapshot.
+type streamer interface {
+	Stream(ctx context.Context, query string, args []interface{}, w db.RowWriter) error
+}
+
+func dumpData(ctx context.Context, source streamer, metadata db.MetadataProvider, dialect db.Dialect, out *bufio.Writer, table string, opts Options) error {
+	columns, err := metadata.GetColumns(ctx, "", table)
+	if err != nil {
+		return err
+	}
+	// Generated columns cannot be inserted into
+	var quoted []string
+	for _, col := range columns {
+		if !col.IsGenerated() {
+			quoted = append(quoted, dialect.QuoteIdentifier(col.Name))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	query := "SELECT " + strings.Join(quoted, ", ") + " FROM " + dialect.QuoteIdentifier(table)
+	if opts.Where != "" {
+		query += " WHERE " + opts.Where
+	}
+
+	w := &insertWriter{
+		out:       out,
+		dialect:   dialect,
+		table:     dialect.QuoteIdentifier(table),
+		batchSize: opts.BatchSize,
+	}
+	fmt.Fprintf(out, "--\n-- Data for %s\n--\n\n", table)
+	if err := source.Stream(ctx, query, nil, w); err != nil {
+		return err
+	}
+	w.flush()
+	out.WriteString("\n")
+	return nil
+}
+
+// dumpViews writes a placeholder for every view before the real
+// definitions, as mysqldump does, so that views may select from views
+// defined later. A placeholder has the columns of its view.
+func dumpViews(ctx context.Context, metadata db.MetadataProvider, dialect db.Dialect, out *bufio.Writer, views []string) error {
+	for _, view := range views {
+		columns, err := metadata.GetColumns(ctx, "", view)
+		if err != nil {
+			return fmt.Errorf("view %s: %w", view, err)
+		}
+		selected := make([]string, len(columns))
+		for i, col := range columns {
+			selected[i] = "1 AS " + dialect.QuoteIdentifier(col.Name)
+		}
+		if len(selected) == 0 {
+			selected = []string{"1"}
+		}
+		fmt.Fprintf(out, "--\n-- Temporary view structure for %s\n--\n\n", view)
+		fmt.Fprintf(out, "DROP VIEW IF EXISTS %s;\nCREATE VIEW %s AS SELECT %s;\n\n",
+			dialect.QuoteIdentifier(view), dialect.QuoteIdentifier(view), strings.Join(selected, ", "))
+	}
+
+	for _, view := range views {
+		ddl, err := metadata.GetDDL(ctx, db.ObjectView, "", view)
+		if err != nil {
+			return fmt.Errorf("view %s: %w", view, err)
+		}
+		fmt.Fprintf(out, "--\n-- View structure for %s\n--\n\n", view)
+		fmt.Fprintf(out, "DROP VIEW IF EXISTS %s;\n%s;\n\n", dialect.QuoteIdentifier(view), stripDefiner(ddl.SQL))
+	}
+	return nil
+}
+
+func dumpTriggers(ctx context.Context, metadata db.MetadataProvider, dialect db.Dialect, out *bufio.Writer, tables []string) error {
+	triggers, err := metadata.GetTriggers(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		selected[strings.ToLower(t)] = true
+	}
+
+	for _, trigger := range triggers {
+		if !selected[strings.ToLower(trigger.Table)] {
+			continue
+		}
+		ddl, err := metadata.GetDDL(ctx, db.ObjectTrigger, "", trigger.Name)
+		if err != nil {
+			return fmt.Errorf("trigger %s: %w", trigger.Name, err)
+		}
+		fmt.Fprintf(out, "--\n-- Trigger %s\n--\n\n", trigger.Name)
+		fmt.Fprintf(out, "DROP TRIGGER IF EXISTS %s;\nDELIMITER ;;\n%s;;\nDELIMITER ;\n\n", dialect.QuoteIdentifier(trigger.Name), stripDefiner(ddl.SQL))
+	}
+	return nil
+}
+
+func dumpRoutines(ctx context.Context, metadata db.MetadataProvider, dialect db.Dialect, out *bufio.Writer) error {
+	routines, err := metadata.GetRoutines(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	labels := map[db.ObjectType]string{db.ObjectProcedure: "Procedure", db.ObjectFunction: "Function"}
+	for _, routine := range routines {
+		ddl, err := metadata.GetDDL(ctx, routine.Type, "", routine.Name)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", strings.ToLower(string(routine.Type)), routine.Name, err)
+		}
+		fmt.Fprintf(out, "--\n-- %s %s\n--\n\n", labels[routine.Type], routine.Name)
+		fmt.Fprintf(out, "DROP %s IF EXISTS %s;\nDELIMITER ;;\n%s;;\nDELIMITER ;\n\n", routine.Type, dialect.QuoteIdentifier(routine.Name), stripDefiner(ddl.SQL))
+	}
+	return nil
+}
+
+func stripDefiner(ddl string) string {
+	return definerPattern.ReplaceAllString(ddl, "")
+}
+
+// insertWriter turns streamed rows into batched INSERT statements.
+type insertWriter struct {
+	out       *bufio.Writer
+	dialect   db.Dialect
+	table     string
+	batchSize int
+	prefix    string
+	rows      int
+}
+
+func (w *insertWriter) WriteHeader(columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = w.dialect.QuoteIdentifier(col)
+	}
+	w.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", w.table, strings.Join(quoted, ", "))
+	return nil
+}
+
+func (w *insertWriter) WriteRow(values []interface{}) error {
+	if w.rows == 0 {
+		w.out.WriteString(w.prefix)
+	} else {
+		w.out.WriteString(",\n")
+	}
+
+	w.out.WriteString("(")
+	for i, v := range values {
+		if i > 0 {
+			w.out.WriteString(", ")
+		}
+		w.out.WriteString(Literal(v))
+	}
+	w.out.WriteString(")")
+
+	w.rows++
+	if w.rows >= w.batchSize {
+		w.flush()
+	}
+	return nil
+}
+
+func (w *insertWriter) flush() {
+	if w.rows > 0 {
+		w.out.WriteString(";\n")
+		w.rows = 0
+	}
+}
+
+// Literal formats a value as a MySQL literal.
+// Text that is not valid UTF-8 is written as a hex literal.
+func Literal(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case int64, int32, int, uint64, float64, float32:
+		return fmt.Sprintf("%v", val)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return "'" + val.Format("2006-01-02 15:04:05.999999") + "'"
+	case []byte:
+		return hexLiteral(val)
+	case string:
+		if !utf8.ValidString(val) {
+			return hexLiteral([]byte(val))
+		}
+		return "'" + literalEscaper.Replace(val) + "'"
+	default:
+		return "'" + literalEscaper.Replace(fmt.Sprintf("%v", val)) + "'"
+	}
+}
+
+var literalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+func hexLiteral(b []byte) string {
+	if len(b) == 0 {
+		return "''"
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+// FlagSet returns a flag set with the dump options. The returned function
+// resolves the options after parsing.
+func FlagSet(name string, errorHandling flag.ErrorHandling) (*flag.FlagSet, func() Options) {
+	fs := flag.NewFlagSet(name, errorHandling)
+	tables := fs.String("tables", "", "Comma-separated tables or views to dump (default: all, including routines)")
+	noData := fs.Bool("no-data", false, "Dump schema only")
+	where := fs.String("where", "", "Condition applied to the rows of every table")
+	batchSize := fs.Int("batch-size", 1000, "Rows per INSERT statement")
+
+	return fs, func() Options {
+		opts := Options{
+			NoData:    *noData,
+			Where:     *where,
+			BatchSize: *batchSize,
+		}
+		if *tables != "" {
+			opts.Tables = strings.Split(*tables, ",")
+		}
+		return opts
+	}
+}
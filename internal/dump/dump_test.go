@@ -0,0 +1,132 @@
+package dump
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "nil", value: nil, expected: "NULL"},
+		{name: "integer", value: int64(42), expected: "42"},
+		{name: "string", value: "hello", expected: "'hello'"},
+		{name: "quote and backslash", value: `it's a \ test`, expected: `'it\'s a \\ test'`},
+		{name: "newline", value: "a\nb", expected: `'a\nb'`},
+		{name: "invalid UTF-8 as hex", value: "\xff\x00", expected: "0xff00"},
+		{name: "bytes as hex", value: []byte{0x01, 0xab}, expected: "0x01ab"},
+		{name: "empty bytes", value: []byte{}, expected: "''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Literal(tt.value); result != tt.expected {
+				t.Errorf("Literal(%v) = %s, want %s", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripDefiner(t *testing.T) {
+	input := "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select 1"
+	expected := "CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v` AS select 1"
+	if result := stripDefiner(input); result != expected {
+		t.Errorf("stripDefiner() = %s, want %s", result, expected)
+	}
+}
+
+type fakeConnector struct {
+	db.Connector
+	snapshot *fakeSnapshot
+}
+
+func (c *fakeConnector) GetCurrentDatabase(ctx context.Context) (string, error) { return "shop", nil }
+func (c *fakeConnector) DB() *sql.DB                                            { return nil }
+func (c *fakeConnector) BeginSnapshot(ctx context.Context) (db.Snapshot, error) {
+	return c.snapshot, nil
+}
+
+type fakeSnapshot struct {
+	queries []string
+	closed  bool
+}
+
+func (s *fakeSnapshot) Stream(ctx context.Context, query string, args []interface{}, w db.RowWriter) error {
+	s.queries = append(s.queries, query)
+	w.WriteHeader([]string{"id", "price", "qty", "created_at"})
+	return w.WriteRow([]interface{}{int64(1), "9.50", int64(2), nil})
+}
+
+func (s *fakeSnapshot) Close() error {
+	s.closed = true
+	return nil
+}
+
+type fakeMetadata struct {
+	db.MetadataProvider
+}
+
+func (m *fakeMetadata) GetTables(ctx context.Context, schema string) ([]string, error) {
+	return []string{"active_orders", "big_orders", "orders"}, nil
+}
+
+func (m *fakeMetadata) GetViews(ctx context.Context, schema string) ([]string, error) {
+	return []string{"active_orders", "big_orders"}, nil
+}
+
+func (m *fakeMetadata) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	if tableName != "orders" {
+		return []db.ColumnInfo{{Name: "id"}, {Name: "total"}}, nil
+	}
+	return []db.ColumnInfo{
+		{Name: "id"},
+		{Name: "price"},
+		{Name: "qty"},
+		{Name: "total", Extra: "STORED GENERATED"},
+		{Name: "created_at", Extra: "DEFAULT_GENERATED"},
+	}, nil
+}
+
+func (m *fakeMetadata) GetDDL(ctx context.Context, objectType db.ObjectType, schema, name string) (*db.ObjectDDL, error) {
+	return &db.ObjectDDL{SQL: "CREATE " + string(objectType) + " `" + name + "` ..."}, nil
+}
+
+func (m *fakeMetadata) GetTriggers(ctx context.Context, schema string) ([]db.TriggerInfo, error) {
+	return nil, nil
+}
+
+func TestRunSkipsGeneratedColumns(t *testing.T) {
+	conn := &fakeConnector{snapshot: &fakeSnapshot{}}
+	var out strings.Builder
+	opts := Options{Tables: []string{"orders", "active_orders", "big_orders"}}
+	if err := Run(context.Background(), conn, &fakeMetadata{}, mysql.NewDialect(), &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	dump := out.String()
+
+	want := "SELECT `id`, `price`, `qty`, `created_at` FROM `orders`"
+	if len(conn.snapshot.queries) != 1 || conn.snapshot.queries[0] != want {
+		t.Errorf("queries = %v, want [%s]", conn.snapshot.queries, want)
+	}
+	if !conn.snapshot.closed {
+		t.Error("snapshot was not closed")
+	}
+	if !strings.Contains(dump, "INSERT INTO `orders` (`id`, `price`, `qty`, `created_at`) VALUES\n(1, '9.50', 2, NULL);") {
+		t.Errorf("dump has no INSERT without the generated column:\n%s", dump)
+	}
+
+	// Every placeholder precedes every real view
+	placeholder := strings.LastIndex(dump, "CREATE VIEW `big_orders` AS SELECT 1 AS `id`, 1 AS `total`;")
+	view := strings.Index(dump, "CREATE VIEW `active_orders` ...")
+	if placeholder < 0 || view < 0 || placeholder > view {
+		t.Errorf("placeholder views are not created first:\n%s", dump)
+	}
+}
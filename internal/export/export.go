@@ -0,0 +1,279 @@
+package export
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/render"
+)
+
+// progressInterval is the number of rows between progress messages.
+const progressInterval = 50000
+
+var (
+	ErrSourceRequired = errors.New("either -query or -table is required")
+	ErrBothSources    = errors.New("-query and -table are mutually exclusive")
+)
+
+// ColumnSource provides the columns of a table.
+type ColumnSource interface {
+	GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error)
+}
+
+// Options holds configuration for an export.
+type Options struct {
+	Query  string
+	Table  string
+	Format render.OutputFormat
+	// Output is the destination file. Empty or "-" writes to stdout.
+	// A .gz suffix compresses the output.
+	Output string
+	// ChunkSize is the number of rows fetched per primary key range in
+	// table mode. 0 reads the table with a single query.
+	ChunkSize int
+	// Progress receives progress messages. May be nil.
+	Progress io.Writer
+}
+
+// Result summarizes an export.
+type Result struct {
+	Rows     int64
+	Chunks   int
+	Duration time.Duration
+}
+
+// Run streams the query or table to the output file.
+func Run(ctx context.Context, conn db.Connector, columns ColumnSource, dialect db.Dialect, opts Options) (*Result, error) {
+	start := time.Now()
+	result := &Result{}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if opts.Query == "" && opts.Table == "" {
+		return result, ErrSourceRequired
+	}
+	if opts.Query != "" && opts.Table != "" {
+		return result, ErrBothSources
+	}
+
+	out, err := openOutput(opts.Output)
+	if err != nil {
+		return result, err
+	}
+
+	stream, err := render.NewStreamWriter(opts.Format, out)
+	if err != nil {
+		out.Close()
+		return result, err
+	}
+	writer := &progressWriter{RowWriter: stream, progress: opts.Progress, start: start}
+
+	if opts.Query != "" {
+		result.Chunks = 1
+		err = conn.Stream(ctx, opts.Query, nil, writer)
+	} else {
+		err = exportTable(ctx, conn, columns, dialect, opts, writer, result)
+	}
+	result.Rows = writer.rows
+
+	if cerr := stream.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return result, err
+}
+
+// exportTable reads the table in primary key order, one chunk per query,
+// so that no single long-running statement holds a snapshot of the whole table.
+func exportTable(ctx context.Context, conn db.Connector, columns ColumnSource, dialect db.Dialect, opts Options, w *progressWriter, result *Result) error {
+	table := db.QuoteQualifiedName(dialect, opts.Table)
+
+	schema, name := db.SplitQualifiedName(opts.Table)
+	cols, err := columns.GetColumns(ctx, schema, name)
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	if len(cols) == 0 {
+		return fmt.Errorf("table not found: %s", opts.Table)
+	}
+
+	var pk []string
+	for _, col := range cols {
+		if col.IsPrimary {
+			pk = append(pk, col.Name)
+		}
+	}
+
+	if opts.ChunkSize <= 0 || len(pk) == 0 {
+		result.Chunks = 1
+		return conn.Stream(ctx, "SELECT * FROM "+table, nil, w)
+	}
+
+	quotedPK := make([]string, len(pk))
+	for i, name := range pk {
+		quotedPK[i] = dialect.QuoteIdentifier(name)
+	}
+	keyList := strings.Join(quotedPK, ", ")
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(pk)), ", ")
+
+	chunk := &chunkWriter{next: w, pk: pk}
+	var last []interface{}
+	for {
+		query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT %d", table, keyList, opts.ChunkSize)
+		if last != nil {
+			query = fmt.Sprintf("SELECT * FROM %s WHERE (%s) > (%s) ORDER BY %s LIMIT %d",
+				table, keyList, marks, keyList, opts.ChunkSize)
+		}
+
+		chunk.rows = 0
+		if err := conn.Stream(ctx, query, last, chunk); err != nil {
+			return err
+		}
+		result.Chunks++
+
+		if chunk.rows < opts.ChunkSize {
+			return nil
+		}
+		last = append([]interface{}(nil), chunk.last...)
+	}
+}
+
+// chunkWriter forwards rows of consecutive chunk queries as one result
+// and remembers the primary key of the last row.
+type chunkWriter struct {
+	next      db.RowWriter
+	pk        []string
+	pkIdx     []int
+	last      []interface{}
+	rows      int
+	headerOut bool
+}
+
+func (c *chunkWriter) WriteHeader(columns []string) error {
+	if c.pkIdx == nil {
+		for _, name := range c.pk {
+			for i, col := range columns {
+				if strings.EqualFold(col, name) {
+					c.pkIdx = append(c.pkIdx, i)
+					break
+				}
+			}
+		}
+		if len(c.pkIdx) != len(c.pk) {
+			return fmt.Errorf("primary key columns missing from result")
+		}
+		c.last = make([]interface{}, len(c.pkIdx))
+	}
+	if c.headerOut {
+		return nil
+	}
+	c.headerOut = true
+	return c.next.WriteHeader(columns)
+}
+
+func (c *chunkWriter) WriteRow(values []interface{}) error {
+	c.rows++
+	for i, idx := range c.pkIdx {
+		c.last[i] = values[idx]
+	}
+	return c.next.WriteRow(values)
+}
+
+// progressWriter counts rows and reports progress.
+type progressWriter struct {
+	db.RowWriter
+	progress io.Writer
+	start    time.Time
+	rows     int64
+}
+
+func (p *progressWriter) WriteRow(values []interface{}) error {
+	p.rows++
+	if p.progress != nil && p.rows%progressInterval == 0 {
+		fmt.Fprintf(p.progress, "Exported %d row(s) (%v)\n", p.rows, time.Since(p.start).Round(time.Millisecond))
+	}
+	return p.RowWriter.WriteRow(values)
+}
+
+// openOutput opens the destination, compressing it when the name ends in .gz.
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return f, nil
+	}
+	return &gzipFile{Writer: gzip.NewWriter(f), file: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	err := g.Writer.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// FormatForFile returns the format implied by the output file name.
+func FormatForFile(path string) render.OutputFormat {
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	if filepath.Ext(name) == ".json" {
+		return render.FormatJSON
+	}
+	return render.FormatCSV
+}
+
+// FlagSet returns a flag set with the export options. The returned
+// function resolves the options after parsing.
+func FlagSet(name string, errorHandling flag.ErrorHandling) (*flag.FlagSet, func() (Options, error)) {
+	fs := flag.NewFlagSet(name, errorHandling)
+	query := fs.String("query", "", "Query to export")
+	table := fs.String("table", "", "Table to export")
+	format := fs.String("format", "", "Output format (csv, json; default: by output file extension)")
+	output := fs.String("o", "", "Output file (default: stdout; .gz compresses)")
+	chunkSize := fs.Int("chunk-size", 10000, "Rows per primary key chunk in table mode (0 = single query)")
+
+	return fs, func() (Options, error) {
+		opts := Options{
+			Query:     *query,
+			Table:     *table,
+			Format:    FormatForFile(*output),
+			Output:    *output,
+			ChunkSize: *chunkSize,
+		}
+		if *format != "" {
+			f, err := render.ParseFormat(*format)
+			if err != nil {
+				return opts, err
+			}
+			opts.Format = f
+		}
+		return opts, nil
+	}
+}
@@ -4,31 +4,81 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/mastar3104/sqcl/internal/completion"
 	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/theme"
 )
 
-// ANSI color codes
+// Save and restore the cursor around text painted past the input
 const (
-	colorReset  = "\033[0m"
-	colorCyan   = "\033[36m" // Keywords
-	colorYellow = "\033[33m" // Strings
-	colorGreen  = "\033[32m" // Numbers
+	saveCursor    = "\0337"
+	restoreCursor = "\0338"
 )
 
 // SQLHighlighter implements syntax highlighting for SQL queries.
 type SQLHighlighter struct {
-	keywords map[string]struct{}
+	keywords  map[string]struct{}
+	functions map[string]db.FunctionInfo
+	hintWidth func() int
+	buffer    func() string
+	unknown   func(statement string, from int) []completion.Span
+	theme     theme.Theme
+}
+
+// mark colors the runes from start to end of a line over their class.
+type mark struct {
+	start int
+	end   int
+	color string
 }
 
 // NewSQLHighlighter creates a new SQL highlighter.
 func NewSQLHighlighter(dialect db.Dialect) *SQLHighlighter {
+	h := &SQLHighlighter{}
+	h.theme, _ = theme.Get(theme.Default)
+	h.SetDialect(dialect)
+	return h
+}
+
+// SetDialect replaces the keywords and functions to highlight.
+func (h *SQLHighlighter) SetDialect(dialect db.Dialect) {
 	keywords := make(map[string]struct{})
 	for _, kw := range dialect.Keywords() {
 		keywords[strings.ToUpper(kw)] = struct{}{}
 	}
-	return &SQLHighlighter{
-		keywords: keywords,
+	functions := make(map[string]db.FunctionInfo)
+	for _, f := range dialect.Functions() {
+		keywords[strings.ToUpper(f.Name)] = struct{}{}
+		functions[strings.ToUpper(f.Name)] = f
 	}
+	h.keywords = keywords
+	h.functions = functions
+}
+
+// SetTheme sets the colors to highlight with.
+func (h *SQLHighlighter) SetTheme(t theme.Theme) {
+	h.theme = t
+}
+
+// SetHintWidth enables signature hints. width returns the number of
+// columns available for the input line; a hint that would not fit on the
+// line is cut.
+func (h *SQLHighlighter) SetHintWidth(width func() int) {
+	h.hintWidth = width
+}
+
+// SetBuffer sets the source of the lines already entered for the current
+// statement, so that a string or comment opened on an earlier line is
+// highlighted as such on the current one.
+func (h *SQLHighlighter) SetBuffer(buffer func() string) {
+	h.buffer = buffer
+}
+
+// SetValidator enables hints for unknown names. unknown returns the
+// tables and columns of statement from rune offset from on that do not
+// exist. It is called on every keystroke, so it must not block.
+func (h *SQLHighlighter) SetValidator(unknown func(statement string, from int) []completion.Span) {
+	h.unknown = unknown
 }
 
 // Paint implements readline.Painter interface.
@@ -37,98 +87,196 @@ func (h *SQLHighlighter) Paint(line []rune, pos int) []rune {
 		return line
 	}
 
-	input := string(line)
-	result := h.highlight(input)
+	buffer := ""
+	if h.buffer != nil {
+		buffer = h.buffer()
+	}
+	st := lex([]rune(buffer), stateNone, nil)
+	marks := append(h.brackets(line, pos, st), h.unknownNames(buffer, line, pos)...)
+	result := h.highlight(string(line), st, marks) + h.hint(line, pos)
 	return []rune(result)
 }
 
-func (h *SQLHighlighter) highlight(input string) string {
-	var result strings.Builder
-	i := 0
-	runes := []rune(input)
+// unknownNames marks the names of line that do not exist, except the one
+// being typed at the cursor.
+func (h *SQLHighlighter) unknownNames(buffer string, line []rune, pos int) []mark {
+	if h.unknown == nil || h.theme.Unknown == "" {
+		return nil
+	}
+	statement, from := string(line), 0
+	if buffer != "" {
+		statement = buffer + "\n" + string(line)
+		from = len([]rune(buffer)) + 1
+	}
 
-	for i < len(runes) {
-		ch := runes[i]
+	var marks []mark
+	for _, span := range h.unknown(statement, from) {
+		start, end := span.Start-from, span.End-from
+		if start <= pos && pos <= end {
+			continue
+		}
+		marks = append(marks, mark{start, end, h.theme.Unknown})
+	}
+	return marks
+}
 
-		// String literal (single quote)
-		if ch == '\'' {
-			start := i
-			i++
-			for i < len(runes) && runes[i] != '\'' {
-				if runes[i] == '\\' && i+1 < len(runes) {
-					i += 2
-				} else {
-					i++
-				}
+// brackets marks the parenthesis just before or at the cursor together
+// with the one matching it. Parentheses in strings and comments are
+// ignored, and nothing is marked when the match is on another line.
+func (h *SQLHighlighter) brackets(line []rune, pos int, st state) []mark {
+	if h.theme.Match == "" {
+		return nil
+	}
+	var parens []int
+	offset := 0
+	lex(line, st, func(c class, text []rune) {
+		if c == classPlain && (text[0] == '(' || text[0] == ')') {
+			parens = append(parens, offset)
+		}
+		offset += len(text)
+	})
+
+	for _, at := range []int{pos - 1, pos} {
+		for i, p := range parens {
+			if p != at {
+				continue
 			}
-			if i < len(runes) {
-				i++ // include closing quote
+			if m := matchingParen(line, parens, i); m >= 0 {
+				return []mark{{at, at + 1, h.theme.Match}, {m, m + 1, h.theme.Match}}
 			}
-			result.WriteString(colorYellow)
-			result.WriteString(string(runes[start:i]))
-			result.WriteString(colorReset)
-			continue
 		}
+	}
+	return nil
+}
+
+// matchingParen returns the position of the parenthesis matching
+// parens[i], or -1 if it is not in parens.
+func matchingParen(line []rune, parens []int, i int) int {
+	step, open := 1, line[parens[i]]
+	if open == ')' {
+		step = -1
+	}
+	depth := 0
+	for j := i; j >= 0 && j < len(parens); j += step {
+		if line[parens[j]] == open {
+			depth++
+		} else {
+			depth--
+		}
+		if depth == 0 {
+			return parens[j]
+		}
+	}
+	return -1
+}
+
+// hint returns the signature of the function whose arguments the cursor
+// is in, painted after the input without moving the cursor.
+func (h *SQLHighlighter) hint(line []rune, pos int) string {
+	if h.hintWidth == nil {
+		return ""
+	}
+	f, ok := h.functions[strings.ToUpper(enclosingFunction(line[:pos]))]
+	if !ok {
+		return ""
+	}
+
+	text := []rune("  " + f.Signature + " → " + f.Returns)
+	room := h.hintWidth() - len(line) - 1
+	if room < 10 {
+		return ""
+	}
+	if len(text) > room {
+		text = append(text[:room-1], '…')
+	}
+	return saveCursor + theme.Paint(h.theme.Hint, string(text)) + restoreCursor
+}
 
-		// String literal (double quote)
-		if ch == '"' {
-			start := i
+// enclosingFunction returns the name of the function call whose argument
+// list is open at the end of input, e.g. "COUNT" for "SELECT COUNT(DISTINCT".
+func enclosingFunction(input []rune) string {
+	var stack []string
+	for i := 0; i < len(input); i++ {
+		switch ch := input[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
 			i++
-			for i < len(runes) && runes[i] != '"' {
-				if runes[i] == '\\' && i+1 < len(runes) {
-					i += 2
-				} else {
+			for i < len(input) && input[i] != ch {
+				if input[i] == '\\' && ch != '`' {
 					i++
 				}
+				i++
 			}
-			if i < len(runes) {
-				i++ // include closing quote
+		case ch == '(':
+			// The word right before the parenthesis names the function
+			end := i
+			for end > 0 && input[end-1] == ' ' {
+				end--
+			}
+			start := end
+			for start > 0 && (unicode.IsLetter(input[start-1]) || unicode.IsDigit(input[start-1]) || input[start-1] == '_') {
+				start--
+			}
+			stack = append(stack, string(input[start:end]))
+		case ch == ')':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
 			}
-			result.WriteString(colorYellow)
-			result.WriteString(string(runes[start:i]))
-			result.WriteString(colorReset)
-			continue
 		}
+	}
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[len(stack)-1]
+}
 
-		// Number
-		if unicode.IsDigit(ch) || (ch == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])) {
-			start := i
-			hasDecimal := ch == '.'
-			i++
-			for i < len(runes) && (unicode.IsDigit(runes[i]) || (!hasDecimal && runes[i] == '.')) {
-				if runes[i] == '.' {
-					hasDecimal = true
-				}
-				i++
+// Highlight returns input with ANSI colors applied.
+func (h *SQLHighlighter) Highlight(input string) string {
+	return h.highlight(input, stateNone, nil)
+}
+
+// highlight colors input, which starts in state st. Marks take precedence
+// over the colors of the lexed classes; the first mark covering a rune
+// wins.
+func (h *SQLHighlighter) highlight(input string, st state, marks []mark) string {
+	var result strings.Builder
+	offset := 0
+	lex([]rune(input), st, func(c class, text []rune) {
+		color := ""
+		switch c {
+		case classWord:
+			if _, isKeyword := h.keywords[strings.ToUpper(string(text))]; isKeyword {
+				color = h.theme.Keyword
 			}
-			result.WriteString(colorGreen)
-			result.WriteString(string(runes[start:i]))
-			result.WriteString(colorReset)
-			continue
+		case classString:
+			color = h.theme.String
+		case classNumber:
+			color = h.theme.Number
+		case classComment:
+			color = h.theme.Comment
+		case classPlaceholder:
+			color = h.theme.Placeholder
 		}
+		paintMarked(&result, text, offset, color, marks)
+		offset += len(text)
+	})
+	return result.String()
+}
 
-		// Identifier or keyword
-		if unicode.IsLetter(ch) || ch == '_' {
-			start := i
-			i++
-			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
-				i++
+// paintMarked writes text, which starts at offset, in color except where
+// marks cover it.
+func paintMarked(sb *strings.Builder, text []rune, offset int, color string, marks []mark) {
+	for len(text) > 0 {
+		runColor, n := color, len(text)
+		for _, m := range marks {
+			if m.start <= offset && offset < m.end {
+				runColor, n = m.color, min(n, m.end-offset)
+				break
 			}
-			word := string(runes[start:i])
-			if _, isKeyword := h.keywords[strings.ToUpper(word)]; isKeyword {
-				result.WriteString(colorCyan)
-				result.WriteString(word)
-				result.WriteString(colorReset)
-			} else {
-				result.WriteString(word)
+			if offset < m.start && m.start-offset < n {
+				n = m.start - offset
 			}
-			continue
 		}
-
-		// Other characters
-		result.WriteRune(ch)
-		i++
+		sb.WriteString(theme.Paint(runColor, string(text[:n])))
+		text, offset = text[n:], offset+n
 	}
-
-	return result.String()
 }
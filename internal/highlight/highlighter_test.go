@@ -0,0 +1,168 @@
+package highlight
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/completion"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+	"github.com/mastar3104/sqcl/internal/theme"
+)
+
+// Colors of the default theme
+const (
+	colorReset   = "\033[0m"
+	colorCyan    = "\033[36m"
+	colorYellow  = "\033[33m"
+	colorGreen   = "\033[32m"
+	colorMagenta = "\033[35m"
+	colorGray    = "\033[90m"
+)
+
+func TestHighlight(t *testing.T) {
+	h := NewSQLHighlighter(mysql.NewDialect())
+	tests := []struct {
+		name  string
+		input string
+		st    state
+		want  string
+	}{
+		{
+			name:  "keywords inside backticks are not highlighted",
+			input: "SELECT `from`",
+			want:  colorCyan + "SELECT" + colorReset + " `from`",
+		},
+		{
+			name:  "line comment",
+			input: "1 -- select",
+			want:  colorGreen + "1" + colorReset + " " + colorGray + "-- select" + colorReset,
+		},
+		{
+			name:  "block comment",
+			input: "/* a */?",
+			want:  colorGray + "/* a */" + colorReset + colorMagenta + "?" + colorReset,
+		},
+		{
+			name:  "placeholder inside string",
+			input: "'?'",
+			want:  colorYellow + "'?'" + colorReset,
+		},
+		{
+			name:  "string continued from an earlier line",
+			input: "from' AND",
+			st:    stateSingleQuote,
+			want:  colorYellow + "from'" + colorReset + " " + colorCyan + "AND" + colorReset,
+		},
+		{
+			name:  "comment continued from an earlier line",
+			input: "select */",
+			st:    stateBlockComment,
+			want:  colorGray + "select */" + colorReset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.highlight(tt.input, tt.st, nil); got != tt.want {
+				t.Errorf("highlight(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLexState(t *testing.T) {
+	tests := []struct {
+		input string
+		want  state
+	}{
+		{"SELECT 'a", stateSingleQuote},
+		{"SELECT 'a\\'", stateSingleQuote},
+		{"SELECT 'a' \"b", stateDoubleQuote},
+		{"SELECT `x", stateBacktick},
+		{"SELECT /* x\ny", stateBlockComment},
+		{"SELECT 1 -- 'x\n", stateNone},
+		{"SELECT 1 # /*", stateNone},
+	}
+
+	for _, tt := range tests {
+		if got := lex([]rune(tt.input), stateNone, nil); got != tt.want {
+			t.Errorf("lex(%q) state = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPaintCarriesBufferState(t *testing.T) {
+	h := NewSQLHighlighter(mysql.NewDialect())
+	h.SetBuffer(func() string { return "SELECT '" })
+
+	got := string(h.Paint([]rune("select'"), 7))
+	if want := colorYellow + "select'" + colorReset; got != want {
+		t.Errorf("Paint() = %q, want %q", got, want)
+	}
+}
+
+func TestThemeWithoutColors(t *testing.T) {
+	h := NewSQLHighlighter(mysql.NewDialect())
+	none, _ := theme.Get("none")
+	h.SetTheme(none)
+
+	input := "SELECT 'a' -- x"
+	if got := h.Highlight(input); got != input {
+		t.Errorf("Highlight() with no colors = %q, want %q", got, input)
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	h := NewSQLHighlighter(mysql.NewDialect())
+	tests := []struct {
+		name string
+		line string
+		pos  int
+		want []int
+	}{
+		{"after closing parenthesis", "f(a, (b))", 9, []int{8, 1}},
+		{"at opening parenthesis", "f(a, (b))", 5, []int{5, 7}},
+		{"before cursor wins", "(a)(b)", 3, []int{2, 0}},
+		{"inside string", "f(')')", 4, nil},
+		{"match on another line", "a))", 3, nil},
+		{"no parenthesis", "abc", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, m := range h.brackets([]rune(tt.line), tt.pos, stateNone) {
+				got = append(got, m.start)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("brackets(%q, %d) = %v, want %v", tt.line, tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaintMarksUnknownNames(t *testing.T) {
+	h := NewSQLHighlighter(mysql.NewDialect())
+	dark, _ := theme.Get("dark")
+	h.SetBuffer(func() string { return "SELECT *" })
+	h.SetValidator(func(statement string, from int) []completion.Span {
+		// "ordres" in "SELECT *\nFROM ordres"
+		if statement != "SELECT *\nFROM ordres" || from != 9 {
+			t.Errorf("validator called with %q from %d", statement, from)
+		}
+		return []completion.Span{{Start: 14, End: 20}}
+	})
+
+	line := []rune("FROM ordres")
+	got := string(h.Paint(line, 0))
+	want := colorCyan + "FROM" + colorReset + " " + dark.Unknown + "ordres" + colorReset
+	if got != want {
+		t.Errorf("Paint() = %q, want %q", got, want)
+	}
+
+	// The word being typed is not marked
+	got = string(h.Paint(line, len(line)))
+	if want := colorCyan + "FROM" + colorReset + " ordres"; got != want {
+		t.Errorf("Paint() at the end of the word = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package highlight
+
+import "unicode"
+
+// state is the construct left open at the end of some input, which
+// continues on the next line of a multi-line statement.
+type state int
+
+const (
+	stateNone state = iota
+	stateSingleQuote
+	stateDoubleQuote
+	stateBacktick
+	stateBlockComment
+)
+
+// class is the kind of a lexed span.
+type class int
+
+const (
+	classPlain class = iota
+	classWord
+	classString
+	classNumber
+	classComment
+	classIdentifier
+	classPlaceholder
+)
+
+// closers maps the open states to the text that closes them.
+var closers = map[state]string{
+	stateSingleQuote:  "'",
+	stateDoubleQuote:  `"`,
+	stateBacktick:     "`",
+	stateBlockComment: "*/",
+}
+
+// lex splits input, which starts in state st, into spans passed to emit
+// and returns the state at its end. emit may be nil to compute the state
+// only.
+func lex(input []rune, st state, emit func(c class, text []rune)) state {
+	if emit == nil {
+		emit = func(class, []rune) {}
+	}
+
+	i := 0
+	if st != stateNone {
+		end, closed := scanClose(input, 0, st)
+		emit(classOf(st), input[:end])
+		if !closed {
+			return st
+		}
+		i = end
+	}
+
+	for i < len(input) {
+		ch := input[i]
+		start := i
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			open := quoteState(ch)
+			end, closed := scanClose(input, i+1, open)
+			emit(classOf(open), input[start:end])
+			if !closed {
+				return open
+			}
+			i = end
+
+		case ch == '/' && i+1 < len(input) && input[i+1] == '*':
+			end, closed := scanClose(input, i+2, stateBlockComment)
+			emit(classComment, input[start:end])
+			if !closed {
+				return stateBlockComment
+			}
+			i = end
+
+		case ch == '#' || (ch == '-' && i+1 < len(input) && input[i+1] == '-' &&
+			(i+2 == len(input) || unicode.IsSpace(input[i+2]))):
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+			emit(classComment, input[start:i])
+
+		case ch == '?':
+			i++
+			emit(classPlaceholder, input[start:i])
+
+		case unicode.IsDigit(ch) || (ch == '.' && i+1 < len(input) && unicode.IsDigit(input[i+1])):
+			hasDecimal := ch == '.'
+			i++
+			for i < len(input) && (unicode.IsDigit(input[i]) || (!hasDecimal && input[i] == '.')) {
+				if input[i] == '.' {
+					hasDecimal = true
+				}
+				i++
+			}
+			emit(classNumber, input[start:i])
+
+		case unicode.IsLetter(ch) || ch == '_':
+			i++
+			for i < len(input) && (unicode.IsLetter(input[i]) || unicode.IsDigit(input[i]) || input[i] == '_') {
+				i++
+			}
+			emit(classWord, input[start:i])
+
+		default:
+			i++
+			emit(classPlain, input[start:i])
+		}
+	}
+	return stateNone
+}
+
+// scanClose returns the position after the text closing st, searching
+// from i, and whether it was found. Backslashes escape characters inside
+// string literals.
+func scanClose(input []rune, i int, st state) (int, bool) {
+	closer := []rune(closers[st])
+	for i < len(input) {
+		if input[i] == '\\' && (st == stateSingleQuote || st == stateDoubleQuote) {
+			i += 2
+			continue
+		}
+		if hasPrefix(input[i:], closer) {
+			return i + len(closer), true
+		}
+		i++
+	}
+	return len(input), false
+}
+
+func hasPrefix(input, prefix []rune) bool {
+	if len(input) < len(prefix) {
+		return false
+	}
+	for i, r := range prefix {
+		if input[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteState(quote rune) state {
+	switch quote {
+	case '\'':
+		return stateSingleQuote
+	case '"':
+		return stateDoubleQuote
+	}
+	return stateBacktick
+}
+
+func classOf(st state) class {
+	switch st {
+	case stateBacktick:
+		return classIdentifier
+	case stateBlockComment:
+		return classComment
+	}
+	return classString
+}
@@ -0,0 +1,271 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+}
+
+var datetimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// ConvertValue converts a raw input value to a Go value suitable for the column type.
+// raw must be nil (NULL) or a string. Text is never taken for NULL: an empty
+// value stays an empty string in string columns and is NULL only in nullable
+// columns of other types, which have no empty value.
+func ConvertValue(raw interface{}, col db.ColumnInfo) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("column %s: unsupported value %v", col.Name, raw)
+	}
+
+	kind := typeKind(col.DataType)
+	trimmed := strings.TrimSpace(s)
+
+	if trimmed == "" {
+		switch {
+		case kind == kindString || kind == kindOther:
+			return s, nil
+		case col.IsNullable:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("column %s: empty value for NOT NULL %s column", col.Name, col.DataType)
+	}
+
+	switch kind {
+	case kindInteger:
+		if b, ok := parseBool(trimmed); ok {
+			return b, nil
+		}
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(trimmed, 10, 64); err == nil {
+			return u, nil
+		}
+		return nil, fmt.Errorf("column %s: invalid integer %q", col.Name, s)
+	case kindDecimal:
+		if _, err := strconv.ParseFloat(trimmed, 64); err != nil {
+			return nil, fmt.Errorf("column %s: invalid number %q", col.Name, s)
+		}
+		// Pass decimals as strings to keep their precision
+		return trimmed, nil
+	case kindFloat:
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: invalid number %q", col.Name, s)
+		}
+		return f, nil
+	case kindBool:
+		if b, ok := parseBool(trimmed); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("column %s: invalid boolean %q", col.Name, s)
+	case kindDate:
+		t, ok := parseTime(trimmed, dateLayouts)
+		if !ok {
+			return nil, fmt.Errorf("column %s: invalid date %q", col.Name, s)
+		}
+		return t.Format("2006-01-02"), nil
+	case kindDatetime:
+		t, ok := parseTime(trimmed, datetimeLayouts)
+		if !ok {
+			return nil, fmt.Errorf("column %s: invalid datetime %q", col.Name, s)
+		}
+		return t.Format("2006-01-02 15:04:05.999999"), nil
+	case kindJSON:
+		if !json.Valid([]byte(trimmed)) {
+			return nil, fmt.Errorf("column %s: invalid JSON", col.Name)
+		}
+		return trimmed, nil
+	default:
+		return s, nil
+	}
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindInteger
+	kindDecimal
+	kindFloat
+	kindBool
+	kindDate
+	kindDatetime
+	kindJSON
+	kindOther
+)
+
+func typeKind(dataType string) valueKind {
+	switch strings.ToLower(dataType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year":
+		return kindInteger
+	case "decimal", "numeric":
+		return kindDecimal
+	case "float", "double", "real":
+		return kindFloat
+	case "bit", "bool", "boolean":
+		return kindBool
+	case "date":
+		return kindDate
+	case "datetime", "timestamp":
+		return kindDatetime
+	case "json":
+		return kindJSON
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return kindString
+	default:
+		return kindOther
+	}
+}
+
+func parseBool(s string) (int64, bool) {
+	switch strings.ToLower(s) {
+	case "true", "1":
+		return 1, true
+	case "false", "0":
+		return 0, true
+	}
+	return 0, false
+}
+
+func parseTime(s string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ColumnDef describes an inferred column for CREATE TABLE.
+type ColumnDef struct {
+	Name string
+	Type string
+}
+
+// columnStats accumulates what the sampled values of one column look like.
+type columnStats struct {
+	seen     bool
+	integer  bool
+	float    bool
+	boolean  bool
+	date     bool
+	datetime bool
+	maxLen   int
+}
+
+func newColumnStats() *columnStats {
+	return &columnStats{integer: true, float: true, boolean: true, date: true, datetime: true}
+}
+
+func (s *columnStats) observe(raw interface{}) {
+	str, ok := raw.(string)
+	if !ok || strings.TrimSpace(str) == "" {
+		return
+	}
+	s.seen = true
+	trimmed := strings.TrimSpace(str)
+	if len([]rune(str)) > s.maxLen {
+		s.maxLen = len([]rune(str))
+	}
+
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err != nil {
+		s.integer = false
+	}
+	if _, err := strconv.ParseFloat(trimmed, 64); err != nil {
+		s.float = false
+	}
+	switch strings.ToLower(trimmed) {
+	case "true", "false":
+	default:
+		s.boolean = false
+	}
+	if _, ok := parseTime(trimmed, dateLayouts); !ok {
+		s.date = false
+	}
+	if _, ok := parseTime(trimmed, datetimeLayouts); !ok {
+		s.datetime = false
+	}
+}
+
+func (s *columnStats) sqlType() string {
+	switch {
+	case !s.seen:
+		return "VARCHAR(255)"
+	case s.boolean:
+		return "BOOLEAN"
+	case s.integer:
+		return "BIGINT"
+	case s.float:
+		return "DOUBLE"
+	case s.date:
+		return "DATE"
+	case s.datetime:
+		return "DATETIME(6)"
+	case s.maxLen <= 255:
+		return "VARCHAR(255)"
+	default:
+		return "TEXT"
+	}
+}
+
+// InferColumns guesses column types from the header and sample records.
+func InferColumns(header []string, sample []*Record) []ColumnDef {
+	stats := make([]*columnStats, len(header))
+	for i := range stats {
+		stats[i] = newColumnStats()
+	}
+	for _, rec := range sample {
+		for i, v := range rec.Values {
+			if i < len(stats) {
+				stats[i].observe(v)
+			}
+		}
+	}
+
+	defs := make([]ColumnDef, len(header))
+	for i, name := range header {
+		defs[i] = ColumnDef{Name: name, Type: stats[i].sqlType()}
+	}
+	return defs
+}
+
+// emptyIsNull reports whether ConvertValue takes an empty value for NULL
+// in col.
+func emptyIsNull(col db.ColumnInfo) bool {
+	kind := typeKind(col.DataType)
+	return col.IsNullable && kind != kindString && kind != kindOther
+}
+
+// columnInfoFromDef converts an inferred definition to the ColumnInfo used for conversion.
+func columnInfoFromDef(def ColumnDef) db.ColumnInfo {
+	dataType := strings.ToLower(def.Type)
+	if i := strings.IndexByte(dataType, '('); i >= 0 {
+		dataType = dataType[:i]
+	}
+	if dataType == "boolean" {
+		dataType = "tinyint"
+	}
+	return db.ColumnInfo{Name: def.Name, DataType: dataType, IsNullable: true}
+}
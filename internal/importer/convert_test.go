@@ -0,0 +1,217 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      interface{}
+		col      db.ColumnInfo
+		expected interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "nil is NULL",
+			raw:      nil,
+			col:      db.ColumnInfo{Name: "id", DataType: "int"},
+			expected: nil,
+		},
+		{
+			name:     "NULL text is kept",
+			raw:      "Null",
+			col:      db.ColumnInfo{Name: "name", DataType: "varchar", IsNullable: true},
+			expected: "Null",
+		},
+		{
+			name:     "empty string kept for nullable string column",
+			raw:      "",
+			col:      db.ColumnInfo{Name: "note", DataType: "varchar", IsNullable: true},
+			expected: "",
+		},
+		{
+			name:     "empty string in nullable column is NULL",
+			raw:      "",
+			col:      db.ColumnInfo{Name: "age", DataType: "int", IsNullable: true},
+			expected: nil,
+		},
+		{
+			name:     "empty string kept for NOT NULL string column",
+			raw:      "",
+			col:      db.ColumnInfo{Name: "name", DataType: "varchar"},
+			expected: "",
+		},
+		{
+			name:    "empty string rejected for NOT NULL integer column",
+			raw:     "",
+			col:     db.ColumnInfo{Name: "age", DataType: "int"},
+			wantErr: true,
+		},
+		{
+			name:     "integer",
+			raw:      " 42 ",
+			col:      db.ColumnInfo{Name: "id", DataType: "bigint"},
+			expected: int64(42),
+		},
+		{
+			name:     "boolean into tinyint",
+			raw:      "true",
+			col:      db.ColumnInfo{Name: "active", DataType: "tinyint"},
+			expected: int64(1),
+		},
+		{
+			name:    "invalid integer",
+			raw:     "12a",
+			col:     db.ColumnInfo{Name: "id", DataType: "int"},
+			wantErr: true,
+		},
+		{
+			name:     "decimal keeps precision",
+			raw:      "12345678901234567890.12",
+			col:      db.ColumnInfo{Name: "price", DataType: "decimal"},
+			expected: "12345678901234567890.12",
+		},
+		{
+			name:     "float",
+			raw:      "3.5",
+			col:      db.ColumnInfo{Name: "score", DataType: "double"},
+			expected: float64(3.5),
+		},
+		{
+			name:     "date with slashes",
+			raw:      "2024/01/31",
+			col:      db.ColumnInfo{Name: "d", DataType: "date"},
+			expected: "2024-01-31",
+		},
+		{
+			name:     "ISO 8601 datetime",
+			raw:      "2024-01-31T10:20:30Z",
+			col:      db.ColumnInfo{Name: "created_at", DataType: "datetime"},
+			expected: "2024-01-31 10:20:30",
+		},
+		{
+			name:    "invalid JSON",
+			raw:     "{",
+			col:     db.ColumnInfo{Name: "data", DataType: "json"},
+			wantErr: true,
+		},
+		{
+			name:     "string passthrough",
+			raw:      " hello ",
+			col:      db.ColumnInfo{Name: "name", DataType: "varchar"},
+			expected: " hello ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertValue(tt.raw, tt.col)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertValue(%v) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if !tt.wantErr && result != tt.expected {
+				t.Errorf("ConvertValue(%v) = %v (%T), want %v (%T)",
+					tt.raw, result, result, tt.expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInferColumns(t *testing.T) {
+	input := "id,price,active,birthday,created_at,name\n" +
+		"1,9.99,true,2000-01-01,2024-01-01 10:00:00,alice\n" +
+		"2,,false,,2024-01-02 11:00:00,bob\n"
+
+	reader, err := NewRecordReader(strings.NewReader(input), FormatCSV, `\N`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sample []*Record
+	for {
+		rec, err := reader.Next()
+		if err != nil {
+			break
+		}
+		sample = append(sample, rec)
+	}
+
+	defs := InferColumns(reader.Header(), sample)
+	var types []string
+	for _, d := range defs {
+		types = append(types, d.Type)
+	}
+
+	expected := []string{"BIGINT", "DOUBLE", "BOOLEAN", "DATE", "DATETIME(6)", "VARCHAR(255)"}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("InferColumns() types = %v, want %v", types, expected)
+	}
+}
+
+func TestJSONReader(t *testing.T) {
+	input := `{"id": 1, "name": "alice"}
+{"id": 2, "name": null, "tags": ["a"]}`
+
+	reader, err := NewRecordReader(strings.NewReader(input), FormatJSON, `\N`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := reader.Header(); !reflect.DeepEqual(got, []string{"id", "name", "tags"}) {
+		t.Errorf("Header() = %v", got)
+	}
+
+	reader.Next()
+	rec, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{"2", nil, `["a"]`}
+	if !reflect.DeepEqual(rec.Values, expected) {
+		t.Errorf("Next() = %v, want %v", rec.Values, expected)
+	}
+}
+
+func TestDelimitedReaderNullString(t *testing.T) {
+	input := "a,b,c\n\\N,,NULL\n"
+	tests := []struct {
+		nullString string
+		expected   []interface{}
+	}{
+		{`\N`, []interface{}{nil, "", "NULL"}},
+		{"", []interface{}{`\N`, nil, "NULL"}},
+		{"NULL", []interface{}{`\N`, "", nil}},
+	}
+
+	for _, tt := range tests {
+		reader, err := NewRecordReader(strings.NewReader(input), FormatCSV, tt.nullString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec, err := reader.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rec.Values, tt.expected) {
+			t.Errorf("Next() with null string %q = %v, want %v", tt.nullString, rec.Values, tt.expected)
+		}
+	}
+}
+
+func TestBuildStatementsQuoteQualifiedTable(t *testing.T) {
+	dialect := mysql.NewDialect()
+
+	insert := BuildInsert(dialect, "shop.users", []string{"id", "name"}, 2)
+	if want := "INSERT INTO `shop`.`users` (`id`, `name`) VALUES (?, ?), (?, ?)"; insert != want {
+		t.Errorf("BuildInsert() = %q, want %q", insert, want)
+	}
+	create := BuildCreateTable(dialect, "users", []ColumnDef{{Name: "id", Type: "BIGINT"}})
+	if want := "CREATE TABLE `users` (`id` BIGINT)"; create != want {
+		t.Errorf("BuildCreateTable() = %q, want %q", create, want)
+	}
+}
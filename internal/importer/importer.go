@@ -0,0 +1,491 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+const (
+	// sampleSize is the number of records used to infer column types for -create.
+	sampleSize = 1000
+	// maxPlaceholders is the MySQL limit of placeholders per prepared statement.
+	maxPlaceholders = 65535
+)
+
+var (
+	ErrTableRequired = errors.New("table name is required")
+	ErrTableNotFound = errors.New("table not found")
+	ErrNoColumns     = errors.New("no input columns match the table columns")
+)
+
+// ColumnSource provides the columns of a table.
+// Both db.MetadataProvider and cache.MetadataCache satisfy it.
+type ColumnSource interface {
+	GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error)
+}
+
+// Options holds configuration for an import.
+type Options struct {
+	Table     string
+	Format    Format
+	Create    bool
+	Truncate  bool
+	DryRun    bool
+	ErrorFile string
+	// BatchSize is the number of rows per multi-row INSERT.
+	BatchSize int
+	// LoadData uses LOAD DATA LOCAL INFILE for CSV/TSV when the server allows it.
+	LoadData bool
+	// NullString is the field value of CSV/TSV files read as NULL.
+	NullString string
+	// Progress receives progress messages. May be nil.
+	Progress io.Writer
+}
+
+// Result summarizes an import.
+type Result struct {
+	Read     int
+	Inserted int64
+	Failed   int
+	Method   string
+	Plan     []string
+	Duration time.Duration
+}
+
+// Summary returns a human-readable summary of the result.
+func (r *Result) Summary(dryRun bool) string {
+	var sb strings.Builder
+	if dryRun {
+		sb.WriteString("Dry run, nothing was executed:\n")
+		for _, stmt := range r.Plan {
+			sb.WriteString("  ")
+			sb.WriteString(stmt)
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%d row(s) read, %d row(s) would fail", r.Read, r.Failed))
+	} else {
+		sb.WriteString(fmt.Sprintf("Import complete (%s): %d row(s) read, %d row(s) inserted", r.Method, r.Read, r.Inserted))
+		if r.Failed > 0 {
+			sb.WriteString(fmt.Sprintf(", %d row(s) failed", r.Failed))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nTime: %v", r.Duration))
+	return sb.String()
+}
+
+// Run imports the file at path into opts.Table.
+func Run(ctx context.Context, conn db.Connector, columns ColumnSource, dialect db.Dialect, path string, opts Options) (*Result, error) {
+	start := time.Now()
+	result := &Result{Method: "insert"}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if opts.Table == "" {
+		return result, ErrTableRequired
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	reader, err := NewRecordReader(f, opts.Format, opts.NullString)
+	if err != nil {
+		return result, err
+	}
+	header := reader.Header()
+
+	schema, name := db.SplitQualifiedName(opts.Table)
+	tableCols, err := columns.GetColumns(ctx, schema, name)
+	if err != nil {
+		return result, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	// Sample records first when the table has to be created
+	var buffered []*Record
+	var statements []string
+	if len(tableCols) == 0 {
+		if !opts.Create {
+			return result, fmt.Errorf("%w: %s (use -create to create it)", ErrTableNotFound, opts.Table)
+		}
+		for len(buffered) < sampleSize {
+			rec, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return result, err
+			}
+			buffered = append(buffered, rec)
+		}
+		defs := InferColumns(header, buffered)
+		statements = append(statements, BuildCreateTable(dialect, opts.Table, defs))
+		for _, def := range defs {
+			tableCols = append(tableCols, columnInfoFromDef(def))
+		}
+	} else if opts.Create {
+		opts.progressf("Table %s already exists, skipping create\n", opts.Table)
+	}
+
+	// Map input fields to table columns
+	byName := make(map[string]db.ColumnInfo, len(tableCols))
+	for _, col := range tableCols {
+		byName[strings.ToLower(col.Name)] = col
+	}
+	var fieldIdx []int
+	var targets []db.ColumnInfo
+	loadColumns := make([]string, len(header))
+	emptyNull := make([]bool, len(header))
+	for i, name := range header {
+		col, ok := byName[strings.ToLower(name)]
+		if !ok {
+			opts.progressf("Warning: column '%s' not found in table %s, skipping\n", name, opts.Table)
+			continue
+		}
+		fieldIdx = append(fieldIdx, i)
+		targets = append(targets, col)
+		loadColumns[i] = col.Name
+		emptyNull[i] = emptyIsNull(col)
+	}
+	if len(targets) == 0 {
+		return result, ErrNoColumns
+	}
+
+	// Rows are deleted rather than truncated, since TRUNCATE commits and
+	// could not be rolled back with the import
+	var clear string
+	if opts.Truncate {
+		clear = "DELETE FROM " + db.QuoteQualifiedName(dialect, opts.Table)
+	}
+
+	if opts.DryRun {
+		result.Plan = append(result.Plan, statements...)
+		if clear != "" {
+			result.Plan = append(result.Plan, clear)
+		}
+	} else {
+		for _, stmt := range statements {
+			if _, err := conn.Execute(ctx, stmt); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// Server-side load for delimited files. It cannot report failed rows.
+	if opts.LoadData && opts.Format != FormatJSON && !opts.DryRun {
+		loader, ok := conn.(db.BulkLoader)
+		switch {
+		case opts.ErrorFile != "":
+			opts.progressf("Warning: LOAD DATA LOCAL INFILE cannot report failed rows, falling back to INSERT\n")
+		case !ok || !loader.BulkLoadAllowed(ctx):
+			opts.progressf("Warning: LOAD DATA LOCAL INFILE is not allowed, falling back to INSERT\n")
+		default:
+			delimiter := ','
+			if opts.Format == FormatTSV {
+				delimiter = '\t'
+			}
+			terminator, err := lineTerminator(path)
+			if err != nil {
+				return result, err
+			}
+			n, err := loader.BulkLoad(ctx, db.BulkLoadRequest{
+				Path:           path,
+				Table:          opts.Table,
+				Columns:        loadColumns,
+				EmptyNull:      emptyNull,
+				NullString:     opts.NullString,
+				Delimiter:      delimiter,
+				LineTerminator: terminator,
+				SkipLines:      1,
+				Clear:          opts.Truncate,
+			})
+			result.Method = "load data"
+			result.Inserted = n
+			result.Read = int(n)
+			return result, err
+		}
+	}
+
+	var report *csv.Writer
+	if opts.ErrorFile != "" {
+		ef, err := os.Create(opts.ErrorFile)
+		if err != nil {
+			return result, err
+		}
+		defer ef.Close()
+		report = csv.NewWriter(ef)
+		defer report.Flush()
+		report.Write(append([]string{"line", "error"}, header...))
+	}
+
+	fail := func(rec *Record, err error) error {
+		result.Failed++
+		if report == nil {
+			if opts.DryRun {
+				opts.progressf("line %d: %v\n", rec.Line, err)
+				return nil
+			}
+			return fmt.Errorf("line %d: %w", rec.Line, err)
+		}
+		fields := []string{strconv.Itoa(rec.Line), err.Error()}
+		for _, v := range rec.Values {
+			if v == nil {
+				fields = append(fields, opts.NullString)
+			} else {
+				fields = append(fields, v.(string))
+			}
+		}
+		report.Write(fields)
+		return nil
+	}
+
+	names := make([]string, len(targets))
+	for i, col := range targets {
+		names[i] = col.Name
+	}
+	chunkSize := opts.BatchSize
+	if chunkSize*len(names) > maxPlaceholders {
+		chunkSize = maxPlaceholders / len(names)
+	}
+	statementCount := 0
+
+	// Rows are deleted and inserted in a single transaction, so that an
+	// import stopped by an error leaves the table as it was
+	var tx db.Transaction
+	if !opts.DryRun {
+		tx, err = conn.Begin(ctx)
+		if err != nil {
+			return result, err
+		}
+		defer func() {
+			if tx != nil {
+				tx.Rollback()
+			}
+		}()
+		if clear != "" {
+			if _, err := tx.ExecuteWithParams(ctx, clear, nil); err != nil {
+				return result, err
+			}
+		}
+	}
+	// abort reports an error after which nothing is left inserted
+	abort := func(err error) (*Result, error) {
+		result.Inserted = 0
+		return result, err
+	}
+
+	var pending []*Record
+	var pendingRows [][]interface{}
+	flush := func() error {
+		if len(pendingRows) == 0 {
+			return nil
+		}
+		statementCount++
+		if opts.DryRun {
+			if statementCount == 1 {
+				result.Plan = append(result.Plan, BuildInsert(dialect, opts.Table, names, 1))
+			}
+			pending, pendingRows = pending[:0], pendingRows[:0]
+			return nil
+		}
+
+		args := make([]interface{}, 0, len(pendingRows)*len(names))
+		for _, row := range pendingRows {
+			args = append(args, row...)
+		}
+		res, err := tx.ExecuteWithParams(ctx, BuildInsert(dialect, opts.Table, names, len(pendingRows)), args)
+		if err == nil {
+			result.Inserted += res.RowsAffected
+		} else if report == nil {
+			return fmt.Errorf("lines %d-%d: %w (nothing was imported)", pending[0].Line, pending[len(pending)-1].Line, err)
+		} else {
+			// Retry row by row to isolate the failing rows
+			single := BuildInsert(dialect, opts.Table, names, 1)
+			for i, row := range pendingRows {
+				// A failed statement does not abort the transaction
+				res, err := tx.ExecuteWithParams(ctx, single, row)
+				if err != nil {
+					fail(pending[i], err)
+					continue
+				}
+				result.Inserted += res.RowsAffected
+			}
+		}
+
+		if result.Inserted/10000 != (result.Inserted-int64(len(pendingRows)))/10000 {
+			opts.progressf("Inserted %d row(s) (%v)\n", result.Inserted, time.Since(start).Round(time.Millisecond))
+		}
+		pending, pendingRows = pending[:0], pendingRows[:0]
+		return nil
+	}
+
+	next := func() (*Record, error) {
+		if len(buffered) > 0 {
+			rec := buffered[0]
+			buffered = buffered[1:]
+			return rec, nil
+		}
+		return reader.Next()
+	}
+
+	for {
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return abort(err)
+		}
+		result.Read++
+
+		row := make([]interface{}, len(targets))
+		var convErr error
+		for i, idx := range fieldIdx {
+			var raw interface{}
+			if idx < len(rec.Values) {
+				raw = rec.Values[idx]
+			}
+			row[i], convErr = ConvertValue(raw, targets[i])
+			if convErr != nil {
+				break
+			}
+		}
+		if convErr != nil {
+			if err := fail(rec, convErr); err != nil {
+				return abort(fmt.Errorf("%w (nothing was imported)", err))
+			}
+			continue
+		}
+
+		pending = append(pending, rec)
+		pendingRows = append(pendingRows, row)
+		if len(pendingRows) >= chunkSize {
+			if err := flush(); err != nil {
+				return abort(err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return abort(err)
+	}
+	if tx != nil {
+		err := tx.Commit()
+		tx = nil
+		if err != nil {
+			return abort(err)
+		}
+	}
+
+	if opts.DryRun && statementCount > 0 {
+		result.Plan[len(result.Plan)-1] += fmt.Sprintf(" -- %d row(s) in %d statement(s)", result.Read-result.Failed, statementCount)
+	}
+	return result, nil
+}
+
+// lineTerminator returns the line ending of the first line of the file at
+// path, "\r\n" or "\n".
+func lineTerminator(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if strings.HasSuffix(line, "\r\n") {
+		return "\r\n", nil
+	}
+	return "\n", nil
+}
+
+func (o Options) progressf(format string, args ...interface{}) {
+	if o.Progress != nil {
+		fmt.Fprintf(o.Progress, format, args...)
+	}
+}
+
+// BuildInsert returns a multi-row INSERT statement with ? placeholders.
+func BuildInsert(dialect db.Dialect, table string, columns []string, rows int) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = dialect.QuoteIdentifier(col)
+	}
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES ", db.QuoteQualifiedName(dialect, table), strings.Join(quoted, ", ")))
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(tuple)
+	}
+	return sb.String()
+}
+
+// BuildCreateTable returns a CREATE TABLE statement for inferred columns.
+func BuildCreateTable(dialect db.Dialect, table string, defs []ColumnDef) string {
+	cols := make([]string, len(defs))
+	for i, def := range defs {
+		cols[i] = dialect.QuoteIdentifier(def.Name) + " " + def.Type
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", db.QuoteQualifiedName(dialect, table), strings.Join(cols, ", "))
+}
+
+// FlagSet returns a flag set with the import options shared by the import
+// subcommand and the :import REPL command. The returned function resolves
+// the options for the given input file after parsing.
+func FlagSet(name string, errorHandling flag.ErrorHandling) (*flag.FlagSet, func(file string) (Options, error)) {
+	fs := flag.NewFlagSet(name, errorHandling)
+	table := fs.String("table", "", "Target table")
+	format := fs.String("format", "", "Input format (csv, tsv, json; default: by file extension)")
+	create := fs.Bool("create", false, "Create the table from inferred column types if it does not exist")
+	truncate := fs.Bool("truncate", false, "Delete the rows of the table before importing, in the same transaction")
+	dryRun := fs.Bool("dry-run", false, "Validate and show the statements without executing them")
+	errorFile := fs.String("errors", "", "Write failed rows to this CSV file and continue")
+	batchSize := fs.Int("batch-size", 500, "Rows per INSERT statement")
+	loadData := fs.Bool("load-data", false, "Use LOAD DATA LOCAL INFILE for CSV/TSV when the server allows it")
+	nullString := fs.String("null-string", `\N`, "CSV/TSV field value loaded as NULL")
+
+	return fs, func(file string) (Options, error) {
+		opts := Options{
+			Table:      *table,
+			Format:     FormatForFile(file),
+			Create:     *create,
+			Truncate:   *truncate,
+			DryRun:     *dryRun,
+			ErrorFile:  *errorFile,
+			BatchSize:  *batchSize,
+			LoadData:   *loadData,
+			NullString: *nullString,
+		}
+		if *format != "" {
+			f, err := ParseFormat(*format)
+			if err != nil {
+				return opts, err
+			}
+			opts.Format = f
+		}
+		if opts.Table == "" {
+			return opts, ErrTableRequired
+		}
+		return opts, nil
+	}
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+// fakeTable is a table whose rows change only when a transaction commits.
+type fakeTable struct {
+	db.Connector
+	rows []string
+}
+
+func (c *fakeTable) Execute(ctx context.Context, query string) (*db.QueryResult, error) {
+	if strings.HasPrefix(query, "TRUNCATE") || strings.HasPrefix(query, "DELETE") {
+		c.rows = nil
+	}
+	return &db.QueryResult{}, nil
+}
+
+func (c *fakeTable) Begin(ctx context.Context) (db.Transaction, error) {
+	return &fakeTableTx{table: c, rows: append([]string(nil), c.rows...)}, nil
+}
+
+type fakeTableTx struct {
+	table *fakeTable
+	rows  []string
+}
+
+func (t *fakeTableTx) ExecuteWithParams(ctx context.Context, query string, args []interface{}) (*db.QueryResult, error) {
+	if strings.HasPrefix(query, "DELETE") {
+		t.rows = nil
+		return &db.QueryResult{}, nil
+	}
+	for _, arg := range args {
+		t.rows = append(t.rows, fmt.Sprint(arg))
+	}
+	return &db.QueryResult{RowsAffected: int64(len(args))}, nil
+}
+
+func (t *fakeTableTx) Commit() error {
+	t.table.rows = t.rows
+	return nil
+}
+
+func (t *fakeTableTx) Rollback() error { return nil }
+
+type fakeColumns []db.ColumnInfo
+
+func (f fakeColumns) GetColumns(ctx context.Context, schema, tableName string) ([]db.ColumnInfo, error) {
+	return f, nil
+}
+
+func TestRunTruncateKeepsRowsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("n\n1\nx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	conn := &fakeTable{rows: []string{"existing"}}
+	columns := fakeColumns{{Name: "n", DataType: "int"}}
+
+	opts := Options{Table: "t", Format: FormatCSV, Truncate: true, NullString: `\N`}
+	if _, err := Run(context.Background(), conn, columns, mysql.NewDialect(), path, opts); err == nil {
+		t.Fatal("Run() succeeded, want a conversion error")
+	}
+	if len(conn.rows) != 1 || conn.rows[0] != "existing" {
+		t.Errorf("rows after failed import = %v, want [existing]", conn.rows)
+	}
+}
+
+func TestLineTerminator(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"a,b\n1,2\n", "\n"},
+		{"a,b\r\n1,2\r\n", "\r\n"},
+		{"a,b", "\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "data.csv")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := lineTerminator(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("lineTerminator(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
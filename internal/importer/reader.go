@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format represents the input file format.
+type Format int
+
+const (
+	FormatCSV Format = iota
+	FormatTSV
+	FormatJSON
+)
+
+// ParseFormat converts a format name to a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return FormatCSV, nil
+	case "tsv":
+		return FormatTSV, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatCSV, fmt.Errorf("unknown format: %s (available: csv, tsv, json)", name)
+	}
+}
+
+// FormatForFile returns the format implied by the file extension.
+func FormatForFile(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv":
+		return FormatTSV
+	case ".json", ".ndjson", ".jsonl":
+		return FormatJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// Record is a single input row. Each value is either nil (NULL) or a string.
+type Record struct {
+	Line   int
+	Values []interface{}
+}
+
+// RecordReader reads records with a header describing their fields.
+type RecordReader interface {
+	Header() []string
+	Next() (*Record, error)
+}
+
+// NewRecordReader creates a reader for the given format. Fields of
+// delimited files equal to nullString are read as NULL; JSON has null.
+func NewRecordReader(r io.Reader, format Format, nullString string) (RecordReader, error) {
+	switch format {
+	case FormatJSON:
+		return newJSONReader(r)
+	case FormatTSV:
+		return newDelimitedReader(r, '\t', nullString)
+	default:
+		return newDelimitedReader(r, ',', nullString)
+	}
+}
+
+type delimitedReader struct {
+	reader     *csv.Reader
+	header     []string
+	nullString string
+}
+
+func newDelimitedReader(r io.Reader, delimiter rune, nullString string) (*delimitedReader, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	if delimiter == '\t' {
+		reader.LazyQuotes = true
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("input is empty")
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+
+	return &delimitedReader{reader: reader, header: header, nullString: nullString}, nil
+}
+
+func (d *delimitedReader) Header() []string {
+	return d.header
+}
+
+func (d *delimitedReader) Next() (*Record, error) {
+	fields, err := d.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := d.reader.FieldPos(0)
+
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		if f == d.nullString {
+			values[i] = nil
+		} else {
+			values[i] = f
+		}
+	}
+	return &Record{Line: line, Values: values}, nil
+}
+
+// jsonReader reads either a JSON array of objects or newline-delimited objects.
+// The whole input is loaded so the header can cover keys from every object.
+type jsonReader struct {
+	header  []string
+	objects []map[string]interface{}
+	pos     int
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	br := bufio.NewReader(r)
+	decoder := json.NewDecoder(br)
+	decoder.UseNumber()
+
+	var objects []map[string]interface{}
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return nil, fmt.Errorf("input is empty")
+	}
+
+	if first == '[' {
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		for {
+			var obj map[string]interface{}
+			if err := decoder.Decode(&obj); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			objects = append(objects, obj)
+		}
+	}
+
+	// Collect keys in order of first appearance
+	var header []string
+	seen := make(map[string]struct{})
+	for _, obj := range objects {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			if _, ok := seen[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		// map iteration is random; keep keys of each object stable
+		sort.Strings(keys)
+		for _, k := range keys {
+			seen[k] = struct{}{}
+			header = append(header, k)
+		}
+	}
+
+	return &jsonReader{header: header, objects: objects}, nil
+}
+
+func (j *jsonReader) Header() []string {
+	return j.header
+}
+
+func (j *jsonReader) Next() (*Record, error) {
+	if j.pos >= len(j.objects) {
+		return nil, io.EOF
+	}
+	obj := j.objects[j.pos]
+	j.pos++
+
+	values := make([]interface{}, len(j.header))
+	for i, key := range j.header {
+		values[i] = jsonValueToString(obj[key])
+	}
+	return &Record{Line: j.pos, Values: values}, nil
+}
+
+func jsonValueToString(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	}
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
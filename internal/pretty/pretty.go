@@ -0,0 +1,445 @@
+// Package pretty reformats SQL statements for reading.
+package pretty
+
+import (
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/completion"
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// conditionIndent is the indentation of AND and OR continuing a condition.
+const conditionIndent = 2
+
+// Options controls how statements are formatted.
+type Options struct {
+	// Dialect identifies reserved words and functions.
+	Dialect db.Dialect
+	// KeywordCase is the case of keywords. CasePreserve leaves them as
+	// written.
+	KeywordCase completion.KeywordCase
+}
+
+// keywords are not reserved but still written in the keyword case.
+var keywords = map[string]bool{
+	"BEGIN": true, "COMMIT": true, "ROLLBACK": true, "TRUNCATE": true,
+	"OFFSET": true, "DUPLICATE": true, "VIEW": true, "TEMPORARY": true,
+}
+
+// tableKeywords start a list of tables, whose names and aliases are never
+// recased even if they are non-reserved keywords such as DATE or TEXT.
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true,
+}
+
+// continuations extend the keyword that starts a clause, as in GROUP BY or
+// LEFT OUTER JOIN.
+var continuations = map[string]bool{
+	"BY": true, "ALL": true, "DISTINCT": true, "DISTINCTROW": true,
+	"OUTER": true, "JOIN": true, "DUPLICATE": true, "KEY": true, "UPDATE": true,
+}
+
+// conditionClauses are the clauses whose AND and OR start a new line.
+var conditionClauses = map[string]bool{
+	"WHERE": true, "HAVING": true, "JOIN": true, "STRAIGHT_JOIN": true,
+	"LEFT": true, "RIGHT": true, "INNER": true, "CROSS": true,
+	"NATURAL": true, "FULL": true,
+}
+
+// item is a token with the whitespace that preceded it.
+type item struct {
+	completion.Token
+	upper   string
+	space   bool
+	newline bool
+}
+
+// level is a parenthesised part of a statement. Clauses of a query level,
+// the statement or a subquery, start on lines of their own.
+type level struct {
+	query     bool
+	statement string
+	clause    string
+	// base is the column of clause keywords.
+	base int
+	// listCol is the column of the first item of the clause, or -1.
+	listCol int
+	// awaiting is set between a clause keyword and its first item.
+	awaiting bool
+	// between is set after BETWEEN until its AND.
+	between bool
+	first   bool
+}
+
+type formatter struct {
+	opts   Options
+	items  []item
+	levels []*level
+	out    strings.Builder
+	col    int
+	// breaks is the number of line breaks to write before the next token
+	// and indent the column to start it at.
+	breaks       int
+	indent       int
+	statementEnd bool
+	// dialectKeywords holds the keywords of the dialect.
+	dialectKeywords map[string]bool
+}
+
+// Format reformats sql with one clause per line, aligned lists and
+// conditions, and keywords in the configured case. Only whitespace and the
+// case of keywords change; comments and string literals are kept exactly.
+func Format(sql string, opts Options) string {
+	f := &formatter{opts: opts, items: items(sql), dialectKeywords: make(map[string]bool)}
+	if opts.Dialect != nil {
+		for _, kw := range opts.Dialect.Keywords() {
+			f.dialectKeywords[kw] = true
+		}
+	}
+	f.reset()
+	for i := range f.items {
+		f.format(i)
+	}
+	return strings.TrimRight(f.out.String(), " \n")
+}
+
+// items attaches the whitespace between tokens to the token following it.
+func items(sql string) []item {
+	var result []item
+	var space, newline bool
+	for _, t := range completion.Tokenize(sql) {
+		if t.Type == completion.TokenWhitespace {
+			space = true
+			newline = newline || strings.Contains(t.Value, "\n")
+			continue
+		}
+		result = append(result, item{Token: t, upper: strings.ToUpper(t.Value), space: space, newline: newline})
+		space, newline = false, false
+	}
+	return result
+}
+
+func (f *formatter) reset() {
+	f.levels = []*level{{query: true, listCol: -1, first: true}}
+}
+
+func (f *formatter) cur() *level {
+	return f.levels[len(f.levels)-1]
+}
+
+func (f *formatter) format(i int) {
+	it := f.items[i]
+	cur := f.cur()
+
+	if it.Type == completion.TokenComment {
+		f.comment(i)
+		return
+	}
+	if f.statementEnd {
+		f.statementEnd = false
+		f.lineBreak(0, 2)
+	}
+
+	switch {
+	case it.Value == ";":
+		f.write(it.Value, false)
+		f.reset()
+		f.statementEnd = true
+		return
+	case it.Value == "(":
+		f.write(it.Value, f.startItem(f.spaceBefore(i)))
+		next := &level{base: cur.base, listCol: -1, first: true}
+		if n, ok := f.next(i); ok && (n.upper == "SELECT" || n.upper == "WITH") {
+			next.query = true
+			next.base = f.col
+		}
+		f.levels = append(f.levels, next)
+		return
+	case it.Value == ")":
+		if len(f.levels) > 1 {
+			f.levels = f.levels[:len(f.levels)-1]
+		}
+		f.write(it.Value, false)
+		return
+	case it.Value == "," && cur.query:
+		f.write(it.Value, false)
+		if cur.listCol >= 0 {
+			f.lineBreak(cur.listCol, 1)
+		} else {
+			f.lineBreak(cur.base+conditionIndent, 1)
+		}
+		return
+	}
+
+	if !cur.query {
+		f.write(f.cased(i), f.spaceBefore(i))
+		return
+	}
+
+	switch {
+	case f.startsClause(i):
+		if !cur.first {
+			f.lineBreak(cur.base, 1)
+		}
+		if cur.first && cur.statement == "" {
+			cur.statement = it.upper
+		}
+		f.write(f.cased(i), f.spaceBefore(i))
+		cur.clause = it.upper
+		cur.awaiting = true
+		cur.listCol = -1
+		cur.between = false
+		return
+	case cur.awaiting && continuations[it.upper]:
+		f.write(f.cased(i), true)
+		return
+	case (it.upper == "AND" || it.upper == "OR" || it.upper == "XOR") && conditionClauses[cur.clause]:
+		if it.upper == "AND" && cur.between {
+			cur.between = false
+		} else {
+			f.lineBreak(cur.base+conditionIndent, 1)
+		}
+	case it.upper == "BETWEEN":
+		cur.between = true
+	}
+
+	if cur.first && cur.statement == "" && it.Type == completion.TokenWord {
+		cur.statement = it.upper
+	}
+	f.write(f.cased(i), f.startItem(f.spaceBefore(i)))
+}
+
+// startItem records the column of the first item of a clause, which the
+// following items are aligned with. It returns whether a space is still to
+// be written before the item.
+func (f *formatter) startItem(space bool) bool {
+	cur := f.cur()
+	if !cur.query || !cur.awaiting {
+		return space
+	}
+	cur.awaiting = false
+	f.flush(space)
+	cur.listCol = f.col
+	return false
+}
+
+// startsClause reports whether item i starts a clause of the current
+// query level.
+func (f *formatter) startsClause(i int) bool {
+	it := f.items[i]
+	cur := f.cur()
+	if it.Type != completion.TokenWord {
+		return false
+	}
+	// LEFT(s, 1) and VALUES(col) are functions
+	if n, ok := f.next(i); ok && n.Value == "(" && !n.space && it.upper != "VALUES" {
+		return false
+	}
+
+	switch it.upper {
+	case "SELECT", "FROM", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT",
+		"UNION", "EXCEPT", "INTERSECT", "WINDOW", "STRAIGHT_JOIN",
+		"LEFT", "RIGHT", "INNER", "CROSS", "NATURAL", "FULL":
+		return !(cur.awaiting && continuations[it.upper])
+	case "JOIN":
+		switch cur.clause {
+		case "LEFT", "RIGHT", "INNER", "CROSS", "NATURAL", "FULL":
+			return !cur.awaiting
+		}
+		return true
+	case "VALUES":
+		// VALUES(col) in ON DUPLICATE KEY UPDATE
+		return cur.clause != "ON"
+	case "ON":
+		n, ok := f.next(i)
+		return ok && n.upper == "DUPLICATE"
+	case "SET":
+		return cur.statement == "UPDATE" || cur.statement == "INSERT" || cur.statement == "REPLACE"
+	}
+	return false
+}
+
+// comment writes a comment where it was: on a line of its own or after the
+// preceding token. Line comments end the line.
+func (f *formatter) comment(i int) {
+	it := f.items[i]
+	if f.statementEnd && it.newline {
+		f.statementEnd = false
+		f.lineBreak(0, 2)
+	}
+	// A comment after a comma stays on the line of the comma
+	breaks, indent := f.breaks, f.indent
+	if it.newline {
+		f.lineBreak(f.lineIndent(), 1)
+		breaks, indent = 0, f.indent
+	} else {
+		f.breaks = 0
+	}
+	first := f.cur().first
+	f.write(it.Value, it.space || it.newline)
+	f.cur().first = first
+	f.breaks, f.indent = breaks, indent
+
+	block := strings.HasPrefix(it.Value, "/*")
+	if n, ok := f.next(i); !block || (ok && n.newline) {
+		f.lineBreak(f.lineIndent(), 1)
+	}
+}
+
+// lineIndent is the column of a line continuing the current clause.
+func (f *formatter) lineIndent() int {
+	cur := f.cur()
+	if cur.listCol >= 0 && !cur.awaiting {
+		return cur.listCol
+	}
+	return cur.base
+}
+
+// spaceBefore reports whether item i is separated from the preceding one.
+func (f *formatter) spaceBefore(i int) bool {
+	it := f.items[i]
+	if i == 0 {
+		return false
+	}
+	prev := f.items[i-1]
+	switch {
+	case it.Value == ")" || it.Value == "," || it.Value == ";":
+		return false
+	case prev.Value == "(":
+		return false
+	case prev.Value == ",":
+		return true
+	case f.spacedOperator(i) || f.spacedOperator(i-1):
+		return true
+	}
+	return it.space
+}
+
+// spacedOperator reports whether item i is a comparison operator that can
+// be surrounded by spaces. Operators next to another operator, as in
+// col->'$.a' or @v:=1, are left alone.
+func (f *formatter) spacedOperator(i int) bool {
+	switch f.items[i].Value {
+	case "=", "<", ">", "<=", ">=", "<>", "!=", "<=>":
+	default:
+		return false
+	}
+	if i == 0 || i+1 >= len(f.items) {
+		return false
+	}
+	prev, next := f.items[i-1], f.items[i+1]
+	if !f.items[i].space && (prev.Type == completion.TokenOperator || prev.Value == ":") {
+		return false
+	}
+	return next.space || next.Type != completion.TokenOperator
+}
+
+// cased returns item i in the keyword case if it is a keyword.
+func (f *formatter) cased(i int) string {
+	it := f.items[i]
+	if f.opts.KeywordCase == completion.CasePreserve || it.Type != completion.TokenWord ||
+		strings.ContainsAny(it.Value, "`.") {
+		return it.Value
+	}
+	keyword := keywords[it.upper] || (f.opts.Dialect != nil && f.opts.Dialect.IsReserved(it.upper))
+	if !keyword && f.dialectKeywords[it.upper] {
+		keyword = !f.isName(i)
+	}
+	if !keyword && f.opts.Dialect != nil {
+		if n, ok := f.next(i); ok && n.Value == "(" && !n.space {
+			_, keyword = db.FindFunction(f.opts.Dialect.Functions(), it.upper)
+		}
+	}
+	if !keyword {
+		return it.Value
+	}
+	return f.opts.KeywordCase.Apply(it.Value, "")
+}
+
+// isName reports whether item i names a table or an alias: a word of a
+// table list, as in "FROM a, date d", or the word after AS in a query.
+// AS in CAST(x AS DATE) is followed by a type.
+func (f *formatter) isName(i int) bool {
+	if p, ok := f.prev(i); ok && p.upper == "AS" {
+		return f.cur().query
+	}
+	depth := 0
+	for j := i - 1; j >= 0; j-- {
+		it := f.items[j]
+		switch {
+		case it.Type == completion.TokenComment:
+		case it.Value == ")":
+			depth++
+		case it.Value == "(":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case depth > 0:
+		case tableKeywords[it.upper]:
+			return true
+		case it.Type == completion.TokenWord:
+			if it.upper != "AS" && (keywords[it.upper] || (f.opts.Dialect != nil && f.opts.Dialect.IsReserved(it.upper))) {
+				return false
+			}
+		case it.Value != ",":
+			return false
+		}
+	}
+	return false
+}
+
+// prev returns the item before i, skipping comments.
+func (f *formatter) prev(i int) (item, bool) {
+	for j := i - 1; j >= 0; j-- {
+		if f.items[j].Type != completion.TokenComment {
+			return f.items[j], true
+		}
+	}
+	return item{}, false
+}
+
+// next returns the item after i, skipping comments.
+func (f *formatter) next(i int) (item, bool) {
+	for j := i + 1; j < len(f.items); j++ {
+		if f.items[j].Type != completion.TokenComment {
+			return f.items[j], true
+		}
+	}
+	return item{}, false
+}
+
+// lineBreak makes the next token start on a new line at column indent.
+// lines is 2 for a blank line.
+func (f *formatter) lineBreak(indent, lines int) {
+	if f.out.Len() == 0 {
+		return
+	}
+	f.breaks = max(f.breaks, lines)
+	f.indent = indent
+}
+
+// flush writes the pending line breaks, or a space if requested.
+func (f *formatter) flush(space bool) {
+	switch {
+	case f.breaks > 0:
+		f.out.WriteString(strings.Repeat("\n", f.breaks))
+		f.out.WriteString(strings.Repeat(" ", f.indent))
+		f.col = f.indent
+		f.breaks = 0
+	case space && f.out.Len() > 0 && f.col > 0:
+		f.out.WriteByte(' ')
+		f.col++
+	}
+}
+
+func (f *formatter) write(text string, space bool) {
+	f.flush(space)
+	f.out.WriteString(text)
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		f.col = len([]rune(text[i+1:]))
+	} else {
+		f.col += len([]rune(text))
+	}
+	f.cur().first = false
+}
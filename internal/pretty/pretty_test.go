@@ -0,0 +1,152 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/completion"
+	"github.com/mastar3104/sqcl/internal/db/mysql"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		keywordCase completion.KeywordCase
+		want        string
+	}{
+		{
+			name:        "clauses and aligned select list",
+			input:       "select id,name , count(*) from users u where u.id=1 and name like 'a  b' order by name",
+			keywordCase: completion.CaseUpper,
+			want: `SELECT id,
+       name,
+       COUNT(*)
+FROM users u
+WHERE u.id = 1
+  AND name LIKE 'a  b'
+ORDER BY name`,
+		},
+		{
+			name:        "joins and between",
+			input:       "SELECT * FROM a LEFT OUTER JOIN b ON b.a_id = a.id AND b.n BETWEEN 1 AND 2 JOIN c USING (id)",
+			keywordCase: completion.CaseLower,
+			want: `select *
+from a
+left outer join b on b.a_id = a.id
+  and b.n between 1 and 2
+join c using (id)`,
+		},
+		{
+			name:  "subquery",
+			input: "SELECT * FROM t WHERE id IN (SELECT t_id FROM u WHERE x = 1)",
+			want: `SELECT *
+FROM t
+WHERE id IN (SELECT t_id
+             FROM u
+             WHERE x = 1)`,
+		},
+		{
+			name:  "update",
+			input: "UPDATE t SET a=1, b='x' WHERE id=2",
+			want: `UPDATE t
+SET a = 1,
+    b = 'x'
+WHERE id = 2`,
+		},
+		{
+			name:  "insert with several rows",
+			input: "INSERT INTO t (a, b) VALUES (1, 2), (3, 4) ON DUPLICATE KEY UPDATE a = VALUES(a)",
+			want: `INSERT INTO t (a, b)
+VALUES (1, 2),
+       (3, 4)
+ON DUPLICATE KEY UPDATE a = VALUES(a)`,
+		},
+		{
+			name:  "comments",
+			input: "-- users\nSELECT a, -- first\n b /* second */ FROM t # done",
+			want: `-- users
+SELECT a, -- first
+       b /* second */
+FROM t # done`,
+		},
+		{
+			name:  "statements",
+			input: "SELECT 1; SELECT 2;",
+			want:  "SELECT 1;\n\nSELECT 2;",
+		},
+		{
+			name:  "functions named like clauses",
+			input: "SELECT LEFT(name, 1), j->>'$.a', @v:=1 FROM t",
+			want: `SELECT LEFT(name, 1),
+       j->>'$.a',
+       @v:=1
+FROM t`,
+		},
+		{
+			name:        "quoted identifiers keep their case",
+			input:       "select `select`, t.order from t",
+			keywordCase: completion.CaseUpper,
+			want:        "SELECT `select`,\n       t.order\nFROM t",
+		},
+		{
+			name:        "non-reserved keywords",
+			input:       "SELECT CASE WHEN a IS NULL THEN 1 ELSE 2 END, DATE_ADD(d, INTERVAL 1 DAY) FROM date",
+			keywordCase: completion.CaseLower,
+			want: `select case when a is null then 1 else 2 end,
+       date_add(d, interval 1 day)
+from date`,
+		},
+		{
+			name:        "comma separated tables named after keywords",
+			input:       "select * from a, date where date.id = 1",
+			keywordCase: completion.CaseUpper,
+			want: `SELECT *
+FROM a,
+     date
+WHERE date.id = 1`,
+		},
+		{
+			name:        "aliases named after keywords",
+			input:       "select cast(y as date) as year from t as year join text on text.id = year.id",
+			keywordCase: completion.CaseUpper,
+			want: `SELECT CAST(y AS DATE) AS year
+FROM t AS year
+JOIN text ON text.id = year.id`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Dialect: mysql.NewDialect(), KeywordCase: tt.keywordCase}
+			got := Format(tt.input, opts)
+			if got != tt.want {
+				t.Errorf("Format(%q) =\n%s\nwant\n%s", tt.input, got, tt.want)
+			}
+			if again := Format(got, opts); again != got {
+				t.Errorf("Format is not idempotent:\n%s\nthen\n%s", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatOnlyChangesWhitespace(t *testing.T) {
+	inputs := []string{
+		"select a,b from t where x='--not a comment' and y=\"#\" -- real\n;",
+		"SELECT /* a\n   b */ 1;select 2",
+		"SELECT COUNT (*) FROM t WHERE a<>b OR a<=>NULL",
+	}
+	strip := func(s string) string {
+		return strings.Join(strings.Fields(s), "")
+	}
+
+	for _, input := range inputs {
+		got := Format(input, Options{Dialect: mysql.NewDialect()})
+		if strip(got) != strip(input) {
+			t.Errorf("Format(%q) = %q changed more than whitespace", input, got)
+		}
+		if !strings.Contains(got, "COUNT (*)") && strings.Contains(input, "COUNT (*)") {
+			t.Errorf("Format(%q) = %q removed the space after a function name", input, got)
+		}
+	}
+}
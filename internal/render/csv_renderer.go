@@ -36,7 +36,7 @@ func (r *CSVRenderer) Render(result *db.QueryResult) string {
 	for _, row := range result.Rows {
 		record := make([]string, len(row))
 		for i, val := range row {
-			record[i] = r.formatValue(val)
+			record[i] = formatValue(val, r.NullDisplay)
 		}
 		writer.Write(record)
 	}
@@ -50,21 +50,6 @@ func (r *CSVRenderer) Render(result *db.QueryResult) string {
 	return output
 }
 
-func (r *CSVRenderer) formatValue(val interface{}) string {
-	if val == nil {
-		return r.NullDisplay
-	}
-
-	switch v := val.(type) {
-	case []byte:
-		return string(v)
-	case string:
-		return v
-	default:
-		return fmt.Sprintf("%v", v)
-	}
-}
-
 func (r *CSVRenderer) renderExecResult(result *db.QueryResult) string {
 	output := fmt.Sprintf("Query OK, %d row(s) affected", result.RowsAffected)
 	if result.LastInsertID > 0 {
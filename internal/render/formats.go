@@ -1,5 +1,10 @@
 package render
 
+import (
+	"fmt"
+	"strings"
+)
+
 // OutputFormat represents the output format type.
 type OutputFormat int
 
@@ -26,3 +31,17 @@ func DefaultFormatConfig() FormatConfig {
 		ShowHeaders: true,
 	}
 }
+
+// ParseFormat converts a format name to an OutputFormat.
+func ParseFormat(name string) (OutputFormat, error) {
+	switch strings.ToLower(name) {
+	case "table":
+		return FormatTable, nil
+	case "csv":
+		return FormatCSV, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatTable, fmt.Errorf("unknown format: %s (available: table, csv, json)", name)
+	}
+}
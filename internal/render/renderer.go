@@ -1,8 +1,27 @@
 package render
 
-import "github.com/mastar3104/sqcl/internal/db"
+import (
+	"fmt"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
 
 // Renderer defines the interface for rendering query results.
 type Renderer interface {
 	Render(result *db.QueryResult) string
 }
+
+// formatValue returns the text of a value shared by the text formats, with
+// NULL written as null.
+func formatValue(val interface{}, null string) string {
+	switch v := val.(type) {
+	case nil:
+		return null
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
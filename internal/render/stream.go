@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// StreamWriter writes query results incrementally to an io.Writer.
+type StreamWriter interface {
+	db.RowWriter
+	// Close writes any trailing output and flushes buffered data.
+	Close() error
+}
+
+// NewStreamWriter creates a streaming writer for the given format.
+// The table format needs every row to size its columns and cannot be streamed.
+func NewStreamWriter(format OutputFormat, w io.Writer) (StreamWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVStreamWriter(w), nil
+	case FormatJSON:
+		return NewJSONStreamWriter(w), nil
+	default:
+		return nil, fmt.Errorf("table format cannot be streamed (use csv or json)")
+	}
+}
+
+// CSVStreamWriter writes rows as CSV.
+type CSVStreamWriter struct {
+	NullDisplay string
+	writer      *csv.Writer
+	record      []string
+}
+
+// NewCSVStreamWriter creates a new CSV stream writer.
+func NewCSVStreamWriter(w io.Writer) *CSVStreamWriter {
+	return &CSVStreamWriter{
+		NullDisplay: "NULL",
+		writer:      csv.NewWriter(w),
+	}
+}
+
+// WriteHeader writes the header row.
+func (s *CSVStreamWriter) WriteHeader(columns []string) error {
+	s.record = make([]string, len(columns))
+	return s.writer.Write(columns)
+}
+
+// WriteRow writes a single row.
+func (s *CSVStreamWriter) WriteRow(values []interface{}) error {
+	for i, val := range values {
+		s.record[i] = formatValue(val, s.NullDisplay)
+	}
+	return s.writer.Write(s.record)
+}
+
+// Close flushes buffered rows.
+func (s *CSVStreamWriter) Close() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// JSONStreamWriter writes rows as a JSON array of objects, keeping column order.
+type JSONStreamWriter struct {
+	writer *bufio.Writer
+	keys   [][]byte
+	// opened is set once the array is opened by WriteHeader.
+	opened  bool
+	started bool
+}
+
+// NewJSONStreamWriter creates a new JSON stream writer.
+func NewJSONStreamWriter(w io.Writer) *JSONStreamWriter {
+	return &JSONStreamWriter{writer: bufio.NewWriter(w)}
+}
+
+// WriteHeader records the column names used as object keys.
+func (s *JSONStreamWriter) WriteHeader(columns []string) error {
+	s.keys = make([][]byte, len(columns))
+	for i, col := range columns {
+		key, err := json.Marshal(col)
+		if err != nil {
+			return err
+		}
+		s.keys[i] = key
+	}
+	_, err := s.writer.WriteString("[")
+	s.opened = err == nil
+	return err
+}
+
+// WriteRow writes a single row as a JSON object.
+func (s *JSONStreamWriter) WriteRow(values []interface{}) error {
+	if s.started {
+		s.writer.WriteString(",")
+	}
+	s.started = true
+	s.writer.WriteString("\n  {")
+	for i, val := range values {
+		if i > 0 {
+			s.writer.WriteString(", ")
+		}
+		s.writer.Write(s.keys[i])
+		s.writer.WriteString(": ")
+		b, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		s.writer.Write(b)
+	}
+	_, err := s.writer.WriteString("}")
+	return err
+}
+
+// Close terminates the JSON array and flushes buffered data. Nothing is
+// written when the header was not, e.g. because the query failed.
+func (s *JSONStreamWriter) Close() error {
+	if !s.opened {
+		return s.writer.Flush()
+	}
+	if s.started {
+		s.writer.WriteString("\n")
+	}
+	s.writer.WriteString("]\n")
+	return s.writer.Flush()
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStreamWriters(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   OutputFormat
+		rows     [][]interface{}
+		expected string
+	}{
+		{
+			name:     "csv",
+			format:   FormatCSV,
+			rows:     [][]interface{}{{int64(1), "a,b"}, {int64(2), nil}},
+			expected: "id,name\n1,\"a,b\"\n2,NULL\n",
+		},
+		{
+			name:     "json keeps column order",
+			format:   FormatJSON,
+			rows:     [][]interface{}{{int64(1), "a"}, {int64(2), nil}},
+			expected: "[\n  {\"id\": 1, \"name\": \"a\"},\n  {\"id\": 2, \"name\": null}\n]\n",
+		},
+		{
+			name:     "json without rows",
+			format:   FormatJSON,
+			expected: "[]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewStreamWriter(tt.format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader([]string{"id", "name"})
+			for _, row := range tt.rows {
+				w.WriteRow(row)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("output = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+
+	if _, err := NewStreamWriter(FormatTable, &bytes.Buffer{}); err == nil {
+		t.Error("NewStreamWriter(FormatTable) should fail")
+	}
+}
+
+func TestJSONStreamWriterWithoutHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONStreamWriter(&buf)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing without a header", buf.String())
+	}
+}
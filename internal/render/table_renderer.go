@@ -5,12 +5,15 @@ import (
 	"strings"
 
 	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/theme"
 )
 
 // TableRenderer renders query results as formatted tables.
 type TableRenderer struct {
 	NullDisplay string
 	MaxColWidth int
+	// Theme colors headers, NULLs and numeric columns.
+	Theme theme.Theme
 }
 
 // NewTableRenderer creates a new table renderer with default settings.
@@ -51,7 +54,7 @@ func (r *TableRenderer) renderTable(sb *strings.Builder, result *db.QueryResult)
 
 	for _, row := range result.Rows {
 		for i, val := range row {
-			str := r.formatValue(val)
+			str := formatValue(val, r.NullDisplay)
 			if len(str) > widths[i] {
 				widths[i] = len(str)
 			}
@@ -62,19 +65,33 @@ func (r *TableRenderer) renderTable(sb *strings.Builder, result *db.QueryResult)
 	separator := r.buildSeparator(widths)
 
 	// Render header
+	headerColors := make([]string, len(result.Columns))
+	for i := range headerColors {
+		headerColors[i] = r.Theme.Header
+	}
 	sb.WriteString(separator)
 	sb.WriteString("\n")
-	r.renderRow(sb, result.Columns, widths, true)
+	r.renderRow(sb, result.Columns, widths, headerColors)
 	sb.WriteString(separator)
 	sb.WriteString("\n")
 
 	// Render data rows
+	numeric := numericColumns(result)
+	colors := make([]string, len(result.Columns))
 	for _, row := range result.Rows {
 		strRow := make([]string, len(row))
 		for i, val := range row {
-			strRow[i] = r.formatValue(val)
+			strRow[i] = formatValue(val, r.NullDisplay)
+			switch {
+			case val == nil:
+				colors[i] = r.Theme.Null
+			case numeric[i]:
+				colors[i] = r.Theme.Numeric
+			default:
+				colors[i] = ""
+			}
 		}
-		r.renderRow(sb, strRow, widths, false)
+		r.renderRow(sb, strRow, widths, colors)
 	}
 
 	sb.WriteString(separator)
@@ -84,7 +101,8 @@ func (r *TableRenderer) renderTable(sb *strings.Builder, result *db.QueryResult)
 	sb.WriteString(fmt.Sprintf("%d row(s) in set", len(result.Rows)))
 }
 
-func (r *TableRenderer) renderRow(sb *strings.Builder, values []string, widths []int, isHeader bool) {
+// renderRow writes a row, each value in its color.
+func (r *TableRenderer) renderRow(sb *strings.Builder, values []string, widths []int, colors []string) {
 	sb.WriteString("|")
 	for i, val := range values {
 		display := val
@@ -92,7 +110,7 @@ func (r *TableRenderer) renderRow(sb *strings.Builder, values []string, widths [
 		// Pad the value
 		padding := widths[i] - len(display)
 		sb.WriteString(" ")
-		sb.WriteString(display)
+		sb.WriteString(theme.Paint(colors[i], display))
 		sb.WriteString(strings.Repeat(" ", padding))
 		sb.WriteString(" |")
 	}
@@ -109,19 +127,43 @@ func (r *TableRenderer) buildSeparator(widths []int) string {
 	return sb.String()
 }
 
-func (r *TableRenderer) formatValue(val interface{}) string {
-	if val == nil {
-		return r.NullDisplay
+// numericColumns reports for each column whether it has a numeric type.
+// Text that looks like a number, such as a VARCHAR code "00123", is not
+// numeric. Without column types, as in results built by commands, a
+// column is numeric when all its non-NULL values are Go numbers.
+func numericColumns(result *db.QueryResult) []bool {
+	numeric := make([]bool, len(result.Columns))
+	if len(result.ColumnTypes) == len(result.Columns) {
+		for i, t := range result.ColumnTypes {
+			numeric[i] = db.IsNumericType(t)
+		}
+		return numeric
 	}
 
-	switch v := val.(type) {
-	case []byte:
-		return string(v)
-	case string:
-		return v
-	default:
-		return fmt.Sprintf("%v", v)
+	for i := range numeric {
+		seen := false
+		numeric[i] = true
+		for _, row := range result.Rows {
+			if row[i] == nil {
+				continue
+			}
+			seen = true
+			if !isNumber(row[i]) {
+				numeric[i] = false
+				break
+			}
+		}
+		numeric[i] = numeric[i] && seen
+	}
+	return numeric
+}
+
+func isNumber(val interface{}) bool {
+	switch val.(type) {
+	case int64, int32, int, float64, float32, uint64:
+		return true
 	}
+	return false
 }
 
 func (r *TableRenderer) renderExecResult(sb *strings.Builder, result *db.QueryResult) {
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/theme"
+)
+
+func TestTableRendererTheme(t *testing.T) {
+	dark, err := theme.Get("dark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &db.QueryResult{
+		Columns:     []string{"id", "name"},
+		ColumnTypes: []string{"INT", "VARCHAR"},
+		Rows:        [][]interface{}{{int64(1), "a"}, {[]byte("2"), nil}},
+		IsSelect:    true,
+	}
+
+	r := NewTableRenderer()
+	r.Theme = dark
+	output := r.Render(result)
+
+	for _, want := range []string{
+		theme.Paint(dark.Header, "id"),
+		theme.Paint(dark.Numeric, "1"),
+		theme.Paint(dark.Numeric, "2"),
+		theme.Paint(dark.Null, "NULL"),
+		"| a ",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+
+	plain := NewTableRenderer().Render(result)
+	if theme.Strip(output) != plain {
+		t.Errorf("colored output differs from plain output once stripped:\n%s\n%s", theme.Strip(output), plain)
+	}
+}
+
+func TestNumericColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *db.QueryResult
+		want   []bool
+	}{
+		{
+			name: "column types",
+			result: &db.QueryResult{
+				Columns:     []string{"id", "code", "price", "qty", "name"},
+				ColumnTypes: []string{"UNSIGNED BIGINT", "VARCHAR", "DECIMAL", "TINYINT", "TEXT"},
+				Rows: [][]interface{}{
+					{"1", "00123", "9.50", nil, "x"},
+				},
+			},
+			want: []bool{true, false, true, true, false},
+		},
+		{
+			name: "without column types",
+			result: &db.QueryResult{
+				Columns: []string{"n", "s", "null", "text"},
+				Rows: [][]interface{}{
+					{int64(1), "x", nil, "1.5"},
+					{float64(2.5), "y", nil, "2"},
+				},
+			},
+			want: []bool{true, false, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := numericColumns(tt.result)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("column %s: got %v, want %v", tt.result.Columns[i], got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
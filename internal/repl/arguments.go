@@ -0,0 +1,49 @@
+package repl
+
+import (
+	"context"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/connections"
+)
+
+// firstArg completes the first argument of a command from fixed values.
+func firstArg(values ...string) func([]string) []string {
+	return func(args []string) []string {
+		if len(args) > 0 {
+			return nil
+		}
+		return values
+	}
+}
+
+// databaseArgs completes the database argument of :tables.
+func (h *CommandHandler) databaseArgs(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	databases, err := h.repl.Cache().GetDatabases(ctx)
+	if err != nil {
+		return nil
+	}
+	return databases
+}
+
+// connectionArgs completes saved connection names.
+func connectionArgs(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	conns, err := connections.NewManager().List()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(conns))
+	for i, c := range conns {
+		names[i] = c.Name
+	}
+	return names
+}
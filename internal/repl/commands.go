@@ -2,67 +2,143 @@ package repl
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
 	"time"
 
+	"github.com/mastar3104/sqcl/internal/batch"
+	"github.com/mastar3104/sqcl/internal/completion"
 	"github.com/mastar3104/sqcl/internal/db"
+	"github.com/mastar3104/sqcl/internal/importer"
 	"github.com/mastar3104/sqcl/internal/render"
 )
 
 // CommandHandler handles internal REPL commands.
 type CommandHandler struct {
-	repl *REPL
+	repl     *REPL
+	commands []command
+	// line is the command line being executed, for commands that take
+	// text verbatim.
+	line string
+}
+
+// command is an entry of the command registry.
+type command struct {
+	names []string
+	run   func(args []string) CommandResult
+	// tables is set when the first argument is a table name.
+	tables bool
+	// args returns the candidates for the argument following args, the
+	// arguments typed so far, for completion.
+	args func(args []string) []string
 }
 
 // NewCommandHandler creates a new command handler.
 func NewCommandHandler(repl *REPL) *CommandHandler {
-	return &CommandHandler{
+	h := &CommandHandler{
 		repl: repl,
 	}
+	noArgs := func(run func() CommandResult) func([]string) CommandResult {
+		return func([]string) CommandResult { return run() }
+	}
+	h.commands = []command{
+		{names: []string{"help", "h", "?"}, run: noArgs(h.helpCommand)},
+		{names: []string{"quit", "q", "exit"}, run: func([]string) CommandResult { return CommandResult{ShouldQuit: true} }},
+		{names: []string{"reload", "refresh"}, run: h.reloadCommand, args: firstArg("--hard")},
+		{names: []string{"tables"}, run: h.tablesCommand, args: h.databaseArgs},
+		{names: []string{"columns", "cols"}, run: h.columnsCommand, tables: true},
+		{names: []string{"describe", "d"}, run: h.describeCommand, tables: true},
+		{names: []string{"databases", "dbs"}, run: noArgs(h.databasesCommand)},
+		{names: []string{"indexes"}, run: h.indexesCommand, tables: true},
+		{names: []string{"fks"}, run: h.foreignKeysCommand, tables: true},
+		{names: []string{"views"}, run: noArgs(h.viewsCommand)},
+		{names: []string{"routines"}, run: noArgs(h.routinesCommand)},
+		{names: []string{"triggers"}, run: noArgs(h.triggersCommand)},
+		{names: []string{"status"}, run: noArgs(h.statusCommand)},
+		{names: []string{"format", "fmt"}, run: h.formatCommand, args: firstArg("table", "csv", "json")},
+		{names: []string{"pretty"}, run: noArgs(h.prettyCommand)},
+		{names: []string{"set"}, run: h.setCommand, args: h.settingArgs},
+		{names: []string{"func"}, run: h.functionCommand, args: h.functionArgs},
+		{names: []string{"connect"}, run: h.connectCommand, args: connectionArgs},
+		{names: []string{"exec-batch"}, run: h.execBatchCommand},
+		{names: []string{"import"}, run: h.importCommand},
+		{names: []string{"ddl", "show-create"}, run: h.ddlCommand, tables: true},
+		{names: []string{"output"}, run: h.outputCommand},
+	}
+	return h
 }
 
 // CommandResult holds the result of a command execution.
 type CommandResult struct {
-	Output   string
+	Output string
+	// Raw is the uncolored output saved for :output. Defaults to Output.
+	Raw        string
 	ShouldQuit bool
-	Error    error
-}
-
-// Execute runs an internal command.
-func (h *CommandHandler) Execute(cmd string, args []string) CommandResult {
-	switch cmd {
-	case "help", "h", "?":
-		return h.helpCommand()
-	case "quit", "q", "exit":
-		return CommandResult{ShouldQuit: true}
-	case "reload", "refresh":
-		return h.reloadCommand()
-	case "tables":
-		return h.tablesCommand()
-	case "columns", "cols":
-		return h.columnsCommand(args)
-	case "databases", "dbs":
-		return h.databasesCommand()
-	case "status":
-		return h.statusCommand()
-	case "format", "fmt":
-		return h.formatCommand(args)
-	default:
-		return CommandResult{Error: fmt.Errorf("unknown command: %s (type :help for available commands)", cmd)}
+	Error      error
+}
+
+// Execute runs the internal command on line.
+func (h *CommandHandler) Execute(line string) CommandResult {
+	cmd, args := ParseCommand(line)
+	h.line = line
+	for _, c := range h.commands {
+		for _, name := range c.names {
+			if name == cmd {
+				return c.run(args)
+			}
+		}
 	}
+	return CommandResult{Error: fmt.Errorf("unknown command: %s (type :help for available commands)", cmd)}
+}
+
+// Commands describes the registered commands for completion.
+func (h *CommandHandler) Commands() []completion.Command {
+	commands := make([]completion.Command, len(h.commands))
+	for i, c := range h.commands {
+		commands[i] = completion.Command{Names: c.names, Tables: c.tables, Args: c.args}
+	}
+	return commands
 }
 
 func (h *CommandHandler) helpCommand() CommandResult {
 	help := `Available commands:
   :help, :h, :?       Show this help message
   :quit, :q, :exit    Exit the client
-  :reload, :refresh   Reload metadata cache
-  :tables             List all tables
+  :reload, :refresh   Reload metadata cache (--hard also deletes the saved cache)
+  :tables [database]  List all tables
   :columns <table>    Show columns for a table
+  :describe, :d <table>
+                      Show columns, indexes, foreign keys and statistics
   :databases, :dbs    List all databases
+  :indexes <table>    Show indexes for a table
+  :fks <table>        Show foreign keys from and to a table
+  :views              List all views
+  :routines           List stored procedures and functions
+  :triggers           List all triggers
   :status             Show connection status
+  :connect <name>     Switch to a saved connection
   :format, :fmt       Show/set output format (table, csv, json)
+  :pretty             Reformat the statement being entered, or the last one,
+                      and put it back for editing
+  :func [name]        Show built-in functions, or the signature of one
+                      (also :func string|date|json|window|aggregate|control)
+  :set [option value] Show or change settings
+                      (completion fuzzy|prefix, keyword-case upper|lower|preserve,
+                       theme dark|light|monochrome|none)
+  :ddl [type] <name>  Show the definition of a table, view, procedure,
+                      function or trigger, optionally as schema.name
+                      (alias :show-create)
+  :output <file>      Save the output of the last query or command to a file
+  :exec-batch [options] <file> <statement>
+                      Run a ? statement once per CSV/TSV row
+                      (--columns, --batch-size, --on-error, --no-header, --tsv)
+  :import -table <table> [options] <file>
+                      Import a CSV/TSV/JSON file into a table
+                      (--format, --create, --truncate, --dry-run, --errors, --load-data)
 
 SQL queries must end with a semicolon (;)
 Use TAB for auto-completion
@@ -70,16 +146,28 @@ Use Ctrl+C to cancel current input, Ctrl+D to exit`
 	return CommandResult{Output: help}
 }
 
-func (h *CommandHandler) reloadCommand() CommandResult {
+func (h *CommandHandler) reloadCommand(args []string) CommandResult {
+	if len(args) > 0 && args[0] == "--hard" {
+		if err := h.repl.Cache().Wipe(); err != nil {
+			return CommandResult{Error: fmt.Errorf("failed to remove metadata cache: %w", err)}
+		}
+		return CommandResult{Output: "Metadata cache and its saved copy removed"}
+	}
+
 	h.repl.Cache().Reload()
 	return CommandResult{Output: "Metadata cache reloaded"}
 }
 
-func (h *CommandHandler) tablesCommand() CommandResult {
+func (h *CommandHandler) tablesCommand(args []string) CommandResult {
+	var schema string
+	if len(args) > 0 {
+		schema = args[0]
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	tables, err := h.repl.Cache().GetTables(ctx)
+	tables, err := h.repl.Cache().GetTables(ctx, schema)
 	if err != nil {
 		return CommandResult{Error: fmt.Errorf("failed to get tables: %w", err)}
 	}
@@ -105,17 +193,17 @@ func (h *CommandHandler) columnsCommand(args []string) CommandResult {
 		return CommandResult{Error: fmt.Errorf("usage: :columns <table_name>")}
 	}
 
-	tableName := args[0]
+	schema, tableName := db.SplitQualifiedName(args[0])
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	columns, err := h.repl.Cache().GetColumns(ctx, tableName)
+	columns, err := h.repl.Cache().GetColumns(ctx, schema, tableName)
 	if err != nil {
 		return CommandResult{Error: fmt.Errorf("failed to get columns: %w", err)}
 	}
 
 	if len(columns) == 0 {
-		return CommandResult{Output: fmt.Sprintf("No columns found for table '%s'", tableName)}
+		return CommandResult{Output: fmt.Sprintf("No columns found for table '%s'", args[0])}
 	}
 
 	result := &db.QueryResult{
@@ -144,6 +232,129 @@ func (h *CommandHandler) columnsCommand(args []string) CommandResult {
 	return CommandResult{Output: output}
 }
 
+func (h *CommandHandler) indexesCommand(args []string) CommandResult {
+	if len(args) == 0 {
+		return CommandResult{Error: fmt.Errorf("usage: :indexes <table_name>")}
+	}
+
+	schema, tableName := db.SplitQualifiedName(args[0])
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexes, err := h.repl.Cache().GetIndexes(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get indexes: %w", err)}
+	}
+
+	if len(indexes) == 0 {
+		return CommandResult{Output: fmt.Sprintf("No indexes found for table '%s'", args[0])}
+	}
+
+	output := h.repl.Renderer().Render(indexesResult(indexes))
+	return CommandResult{Output: output}
+}
+
+func (h *CommandHandler) foreignKeysCommand(args []string) CommandResult {
+	if len(args) == 0 {
+		return CommandResult{Error: fmt.Errorf("usage: :fks <table_name>")}
+	}
+
+	schema, tableName := db.SplitQualifiedName(args[0])
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fks, err := h.repl.Cache().GetForeignKeys(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get foreign keys: %w", err)}
+	}
+
+	if len(fks) == 0 {
+		return CommandResult{Output: fmt.Sprintf("No foreign keys found for table '%s'", args[0])}
+	}
+
+	output := h.repl.Renderer().Render(foreignKeysResult(tableName, fks))
+	return CommandResult{Output: output}
+}
+
+func (h *CommandHandler) viewsCommand() CommandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	views, err := h.repl.Cache().GetViews(ctx, "")
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get views: %w", err)}
+	}
+
+	if len(views) == 0 {
+		return CommandResult{Output: "No views found"}
+	}
+
+	result := &db.QueryResult{
+		Columns:  []string{"View"},
+		IsSelect: true,
+	}
+	for _, v := range views {
+		result.Rows = append(result.Rows, []interface{}{v})
+	}
+
+	output := h.repl.Renderer().Render(result)
+	return CommandResult{Output: output}
+}
+
+func (h *CommandHandler) routinesCommand() CommandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	routines, err := h.repl.Cache().GetRoutines(ctx, "")
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get routines: %w", err)}
+	}
+
+	if len(routines) == 0 {
+		return CommandResult{Output: "No routines found"}
+	}
+
+	result := &db.QueryResult{
+		Columns:  []string{"Routine", "Type", "Returns", "Comment"},
+		IsSelect: true,
+	}
+	for _, r := range routines {
+		result.Rows = append(result.Rows, []interface{}{
+			r.Name, string(r.Type), r.Returns, r.Comment,
+		})
+	}
+
+	output := h.repl.Renderer().Render(result)
+	return CommandResult{Output: output}
+}
+
+func (h *CommandHandler) triggersCommand() CommandResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	triggers, err := h.repl.Cache().GetTriggers(ctx, "")
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get triggers: %w", err)}
+	}
+
+	if len(triggers) == 0 {
+		return CommandResult{Output: "No triggers found"}
+	}
+
+	result := &db.QueryResult{
+		Columns:  []string{"Trigger", "Table", "Timing", "Event"},
+		IsSelect: true,
+	}
+	for _, t := range triggers {
+		result.Rows = append(result.Rows, []interface{}{
+			t.Name, t.Table, t.Timing, t.Event,
+		})
+	}
+
+	output := h.repl.Renderer().Render(result)
+	return CommandResult{Output: output}
+}
+
 func (h *CommandHandler) databasesCommand() CommandResult {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -181,6 +392,10 @@ func (h *CommandHandler) statusCommand() CommandResult {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Connection Status: %s\n", status))
 
+	stats := h.repl.Cache().Stats()
+	sb.WriteString(fmt.Sprintf("Metadata Cache: %d hits, %d misses, %d refreshes\n",
+		stats.Hits, stats.Misses, stats.Refreshes))
+
 	return CommandResult{Output: sb.String()}
 }
 
@@ -212,3 +427,124 @@ func (h *CommandHandler) formatCommand(args []string) CommandResult {
 		return CommandResult{Error: fmt.Errorf("unknown format: %s (available: table, csv, json)", format)}
 	}
 }
+
+func (h *CommandHandler) execBatchCommand(args []string) CommandResult {
+	fs, resolve := batch.FlagSet("exec-batch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return CommandResult{Error: fmt.Errorf("%v (usage: :exec-batch [options] <file> <statement>)", err)}
+	}
+	if fs.NArg() < 2 {
+		return CommandResult{Error: fmt.Errorf("usage: :exec-batch [options] <file> <statement>")}
+	}
+
+	file := fs.Arg(0)
+	// The statement is taken as typed: flags and the file come first
+	statement := CommandRest(h.line, len(args)-fs.NArg()+1)
+	statement = strings.TrimSuffix(statement, ";")
+
+	opts, err := resolve(file)
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	opts.Progress = os.Stderr
+
+	f, err := os.Open(file)
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	defer f.Close()
+
+	ctx, cancel := interruptible()
+	defer cancel()
+
+	result, err := batch.Run(ctx, h.repl.Connector(), f, statement, opts)
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	return CommandResult{Output: result.Summary()}
+}
+
+func (h *CommandHandler) importCommand(args []string) CommandResult {
+	fs, resolve := importer.FlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return CommandResult{Error: fmt.Errorf("%v (usage: :import -table <table> [options] <file>)", err)}
+	}
+	if fs.NArg() != 1 {
+		return CommandResult{Error: fmt.Errorf("usage: :import -table <table> [options] <file>")}
+	}
+
+	file := fs.Arg(0)
+	opts, err := resolve(file)
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	opts.Progress = os.Stderr
+
+	ctx, cancel := interruptible()
+	defer cancel()
+
+	result, err := importer.Run(ctx, h.repl.Connector(), h.repl.Cache(), h.repl.Dialect(), file, opts)
+	if opts.Create && !opts.DryRun {
+		h.repl.Cache().Reload()
+	}
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	return CommandResult{Output: result.Summary(opts.DryRun)}
+}
+
+// interruptible returns a context that Ctrl+C cancels, for commands that
+// run until their input is exhausted.
+func interruptible() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func (h *CommandHandler) ddlCommand(args []string) CommandResult {
+	usage := fmt.Errorf("usage: :ddl [table|view|procedure|function|trigger] <name>")
+	var objectType db.ObjectType
+	switch len(args) {
+	case 1:
+	case 2:
+		t, ok := db.ParseObjectType(args[0])
+		if !ok {
+			return CommandResult{Error: usage}
+		}
+		objectType = t
+		args = args[1:]
+	default:
+		return CommandResult{Error: usage}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schema, name := db.SplitQualifiedName(args[0])
+	ddl, err := h.repl.Cache().GetDDL(ctx, objectType, schema, name)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get definition: %w", err)}
+	}
+
+	raw := ddl.SQL + ";"
+	return CommandResult{
+		Output: h.repl.Highlighter().Highlight(raw),
+		Raw:    raw,
+	}
+}
+
+func (h *CommandHandler) outputCommand(args []string) CommandResult {
+	if len(args) != 1 {
+		return CommandResult{Error: fmt.Errorf("usage: :output <file>")}
+	}
+
+	output := h.repl.LastOutput()
+	if output == "" {
+		return CommandResult{Error: fmt.Errorf("no output to save")}
+	}
+
+	if err := os.WriteFile(args[0], []byte(output+"\n"), 0644); err != nil {
+		return CommandResult{Error: err}
+	}
+	return CommandResult{Output: fmt.Sprintf("Output saved to %s", args[0])}
+}
@@ -0,0 +1,22 @@
+package repl
+
+import (
+	"fmt"
+)
+
+// connectCommand switches to a saved connection.
+func (h *CommandHandler) connectCommand(args []string) CommandResult {
+	if len(args) != 1 {
+		return CommandResult{Error: fmt.Errorf("usage: :connect <connection_name>")}
+	}
+	if h.repl.connect == nil {
+		return CommandResult{Error: fmt.Errorf("switching connections is not available")}
+	}
+
+	session, err := h.repl.connect(args[0])
+	if err != nil {
+		return CommandResult{Error: err}
+	}
+	h.repl.setSession(session)
+	return CommandResult{Output: fmt.Sprintf("Connected to %s", args[0])}
+}
@@ -0,0 +1,156 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+func (h *CommandHandler) describeCommand(args []string) CommandResult {
+	if len(args) == 0 {
+		return CommandResult{Error: fmt.Errorf("usage: :describe <table_name>")}
+	}
+
+	schema, tableName := db.SplitQualifiedName(args[0])
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cache := h.repl.Cache()
+	status, err := cache.GetTableStatus(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get table status: %w", err)}
+	}
+	columns, err := cache.GetColumns(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get columns: %w", err)}
+	}
+	indexes, err := cache.GetIndexes(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get indexes: %w", err)}
+	}
+	fks, err := cache.GetForeignKeys(ctx, schema, tableName)
+	if err != nil {
+		return CommandResult{Error: fmt.Errorf("failed to get foreign keys: %w", err)}
+	}
+
+	renderer := h.repl.Renderer()
+	var sb strings.Builder
+	section := func(title string, result *db.QueryResult) {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(title + ":\n")
+		sb.WriteString(renderer.Render(result))
+	}
+
+	section("Table "+status.Name, tableStatusResult(status))
+	section("Columns", describeColumnsResult(columns))
+	if len(indexes) > 0 {
+		section("Indexes", indexesResult(indexes))
+	}
+	if len(fks) > 0 {
+		section("Foreign keys", foreignKeysResult(tableName, fks))
+	}
+
+	return CommandResult{Output: sb.String()}
+}
+
+func tableStatusResult(status *db.TableStatus) *db.QueryResult {
+	result := &db.QueryResult{
+		Columns:  []string{"Property", "Value"},
+		IsSelect: true,
+	}
+	add := func(name string, value interface{}) {
+		result.Rows = append(result.Rows, []interface{}{name, value})
+	}
+	add("Engine", status.Engine)
+	add("Rows (approx.)", status.Rows)
+	add("Data size", formatBytes(status.DataLength))
+	add("Index size", formatBytes(status.IndexLength))
+	add("Collation", status.Collation)
+	if status.Comment != "" {
+		add("Comment", status.Comment)
+	}
+	return result
+}
+
+func describeColumnsResult(columns []db.ColumnInfo) *db.QueryResult {
+	result := &db.QueryResult{
+		Columns:  []string{"Column", "Type", "Nullable", "Key", "Default", "Extra", "Comment"},
+		IsSelect: true,
+	}
+	for _, col := range columns {
+		colType := col.FullType
+		if colType == "" {
+			colType = col.DataType
+		}
+		nullable := "NO"
+		if col.IsNullable {
+			nullable = "YES"
+		}
+		def := "NULL"
+		if col.Default != nil {
+			def = *col.Default
+		}
+		result.Rows = append(result.Rows, []interface{}{
+			col.Name, colType, nullable, col.Key, def, col.Extra, col.Comment,
+		})
+	}
+	return result
+}
+
+func indexesResult(indexes []db.IndexInfo) *db.QueryResult {
+	result := &db.QueryResult{
+		Columns:  []string{"Index", "Unique", "Columns", "Type"},
+		IsSelect: true,
+	}
+	for _, idx := range indexes {
+		unique := "NO"
+		if idx.Unique {
+			unique = "YES"
+		}
+		result.Rows = append(result.Rows, []interface{}{
+			idx.Name, unique, strings.Join(idx.Columns, ", "), idx.Type,
+		})
+	}
+	return result
+}
+
+// foreignKeysResult lists the foreign keys of tableName and those referencing it.
+func foreignKeysResult(tableName string, fks []db.ForeignKeyInfo) *db.QueryResult {
+	result := &db.QueryResult{
+		Columns:  []string{"Constraint", "Direction", "Columns", "References", "On Update", "On Delete"},
+		IsSelect: true,
+	}
+	for _, fk := range fks {
+		direction := "outgoing"
+		if !strings.EqualFold(fk.Table, tableName) {
+			direction = "incoming"
+		}
+		result.Rows = append(result.Rows, []interface{}{
+			fk.Name,
+			direction,
+			fmt.Sprintf("%s(%s)", fk.Table, strings.Join(fk.Columns, ", ")),
+			fmt.Sprintf("%s(%s)", fk.RefTable, strings.Join(fk.RefColumns, ", ")),
+			fk.OnUpdate,
+			fk.OnDelete,
+		})
+	}
+	return result
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
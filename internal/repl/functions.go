@@ -0,0 +1,67 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/db"
+)
+
+// functionCommand documents built-in functions from the dialect's catalog.
+// Without arguments it lists all functions; a category name lists that
+// category; a function name shows its details.
+func (h *CommandHandler) functionCommand(args []string) CommandResult {
+	functions := h.repl.Dialect().Functions()
+	if len(args) == 0 {
+		return CommandResult{Output: h.repl.Renderer().Render(functionsResult(functions))}
+	}
+
+	name := strings.TrimSuffix(args[0], "(")
+	if f, ok := db.FindFunction(functions, name); ok {
+		var sb strings.Builder
+		sb.WriteString(f.Signature + "\n")
+		sb.WriteString(fmt.Sprintf("  Returns:  %s\n", f.Returns))
+		sb.WriteString(fmt.Sprintf("  Category: %s\n", f.Category))
+		sb.WriteString("  " + f.Description)
+		return CommandResult{Output: sb.String()}
+	}
+
+	var category []db.FunctionInfo
+	for _, f := range functions {
+		if strings.EqualFold(string(f.Category), name) {
+			category = append(category, f)
+		}
+	}
+	if len(category) > 0 {
+		return CommandResult{Output: h.repl.Renderer().Render(functionsResult(category))}
+	}
+	return CommandResult{Error: fmt.Errorf("unknown function: %s", name)}
+}
+
+// functionArgs completes function and category names.
+func (h *CommandHandler) functionArgs(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	var names []string
+	categories := make(map[db.FunctionCategory]bool)
+	for _, f := range h.repl.Dialect().Functions() {
+		names = append(names, f.Name)
+		if !categories[f.Category] {
+			categories[f.Category] = true
+			names = append(names, string(f.Category))
+		}
+	}
+	return names
+}
+
+func functionsResult(functions []db.FunctionInfo) *db.QueryResult {
+	result := &db.QueryResult{
+		Columns:  []string{"Function", "Returns", "Category"},
+		IsSelect: true,
+	}
+	for _, f := range functions {
+		result.Rows = append(result.Rows, []interface{}{f.Signature, f.Returns, string(f.Category)})
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package repl
 
 import (
 	"strings"
+	"unicode"
 )
 
 // InputAccumulator handles multi-line SQL input accumulation.
@@ -58,6 +59,13 @@ func IsInternalCommand(input string) bool {
 	return strings.HasPrefix(trimmed, ":")
 }
 
+// IsBufferCommand checks if the input is an internal command that works on
+// the statement being entered, so that it is recognized while accumulating.
+func IsBufferCommand(input string) bool {
+	cmd, _ := ParseCommand(input)
+	return cmd == "pretty"
+}
+
 // ParseCommand parses an internal command into name and arguments.
 func ParseCommand(input string) (string, []string) {
 	trimmed := strings.TrimSpace(input)
@@ -72,3 +80,20 @@ func ParseCommand(input string) (string, []string) {
 
 	return strings.ToLower(parts[0]), parts[1:]
 }
+
+// CommandRest returns the text of an internal command after its name and
+// first n arguments, with the whitespace in it kept as typed. Commands
+// taking a SQL statement use it so that literals are not altered.
+func CommandRest(input string, n int) string {
+	rest := strings.TrimLeftFunc(input, unicode.IsSpace)
+	rest = strings.TrimPrefix(rest, ":")
+	for i := 0; i <= n; i++ {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		rest = rest[end:]
+	}
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,21 @@
+package repl
+
+import "testing"
+
+func TestCommandRest(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{":exec-batch f.csv UPDATE t SET note = 'a  b' WHERE id = ?", 1, "UPDATE t SET note = 'a  b' WHERE id = ?"},
+		{"  :exec-batch  -tsv\tf.tsv   SELECT  ?;", 2, "SELECT  ?;"},
+		{":exec-batch f.csv", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := CommandRest(tt.input, tt.n); got != tt.want {
+			t.Errorf("CommandRest(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
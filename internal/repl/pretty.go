@@ -0,0 +1,27 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/pretty"
+)
+
+// prettyCommand reformats the statement being entered, or else the last
+// one executed, and puts it back on the prompt for editing.
+func (h *CommandHandler) prettyCommand() CommandResult {
+	statement := h.repl.accumulator.Get()
+	if strings.TrimSpace(statement) == "" {
+		statement = h.repl.lastStatement
+	}
+	if strings.TrimSpace(statement) == "" {
+		return CommandResult{Error: fmt.Errorf("no statement to format")}
+	}
+
+	formatted := pretty.Format(statement, pretty.Options{
+		Dialect:     h.repl.Dialect(),
+		KeywordCase: h.repl.Completer().KeywordCase(),
+	})
+	h.repl.edit(formatted)
+	return CommandResult{}
+}
@@ -15,8 +15,12 @@ import (
 	"github.com/mastar3104/sqcl/internal/highlight"
 	"github.com/mastar3104/sqcl/internal/placeholder"
 	"github.com/mastar3104/sqcl/internal/render"
+	"github.com/mastar3104/sqcl/internal/theme"
 )
 
+// continuationPrompt is shown while a statement spans several lines.
+const continuationPrompt = "   -> "
+
 // REPL represents the read-eval-print loop.
 type REPL struct {
 	connector      db.Connector
@@ -25,8 +29,18 @@ type REPL struct {
 	renderer       render.Renderer
 	outputFormat   render.OutputFormat
 	commandHandler *CommandHandler
+	completer      *completion.SQLCompleter
+	highlighter    *highlight.SQLHighlighter
 	historyFile    string
 	readline       *readline.Instance
+	accumulator    *InputAccumulator
+	lastOutput     string
+	lastStatement  string
+	prompt         string
+	// prefill is the text put on the next prompt for editing.
+	prefill string
+	theme   theme.Theme
+	connect func(name string) (*Session, error)
 }
 
 // Config holds REPL configuration.
@@ -35,6 +49,19 @@ type Config struct {
 	Cache       *cache.MetadataCache
 	Dialect     db.Dialect
 	HistoryFile string
+	// Connect opens a saved connection for :connect. The caller owns the
+	// sessions and closes the previous one.
+	Connect func(name string) (*Session, error)
+	// Theme names the color theme to start with; empty selects the
+	// default.
+	Theme string
+}
+
+// Session is the database the REPL works on.
+type Session struct {
+	Connector db.Connector
+	Cache     *cache.MetadataCache
+	Dialect   db.Dialect
 }
 
 // New creates a new REPL instance.
@@ -51,6 +78,7 @@ func New(cfg Config) (*REPL, error) {
 
 		HistorySearchFold:      true,
 		FuncFilterInputRune:    filterInput,
+		Listener:               completer,
 		Painter:                highlighter,
 		DisableAutoSaveHistory: true,
 	})
@@ -64,10 +92,28 @@ func New(cfg Config) (*REPL, error) {
 		dialect:      cfg.Dialect,
 		renderer:     render.NewTableRenderer(),
 		outputFormat: render.FormatTable,
+		completer:    completer,
+		highlighter:  highlighter,
 		historyFile:  cfg.HistoryFile,
 		readline:     rl,
+		accumulator:  NewInputAccumulator(),
+		connect:      cfg.Connect,
 	}
+	t, err := theme.Select(cfg.Theme, readline.IsTerminal(int(os.Stdout.Fd())))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		t, _ = theme.Select("", readline.IsTerminal(int(os.Stdout.Fd())))
+	}
+	r.SetTheme(t)
+
+	completer.SetBuffer(r.accumulator.Get)
+	highlighter.SetBuffer(r.accumulator.Get)
+	highlighter.SetValidator(completer.Unknown)
+	highlighter.SetHintWidth(func() int {
+		return readline.GetScreenWidth() - len([]rune(r.prompt))
+	})
 	r.commandHandler = NewCommandHandler(r)
+	completer.SetCommands(r.commandHandler.Commands())
 
 	return r, nil
 }
@@ -80,16 +126,18 @@ func (r *REPL) Run() error {
 	fmt.Println("Type :help for help, :quit to exit")
 	fmt.Println()
 
-	accumulator := NewInputAccumulator()
+	accumulator := r.accumulator
 
 	for {
 		prompt := r.getPrompt()
 		if !accumulator.IsEmpty() {
-			prompt = "   -> "
+			prompt = continuationPrompt
 		}
+		r.prompt = prompt
 		r.readline.SetPrompt(prompt)
 
-		line, err := r.readline.Readline()
+		line, err := r.readline.ReadlineWithDefault(r.prefill)
+		r.prefill = ""
 		if err == readline.ErrInterrupt {
 			if accumulator.IsEmpty() {
 				continue
@@ -117,16 +165,23 @@ func (r *REPL) Run() error {
 			continue
 		}
 
-		// Handle internal commands (only when not accumulating)
-		if accumulator.IsEmpty() && IsInternalCommand(line) {
+		// Handle internal commands (only when not accumulating, except for
+		// those working on the statement being entered)
+		if IsInternalCommand(line) && (accumulator.IsEmpty() || IsBufferCommand(line)) {
 			r.readline.SaveHistory(line)
-			cmd, args := ParseCommand(line)
-			result := r.commandHandler.Execute(cmd, args)
+			cmd, _ := ParseCommand(line)
+			result := r.commandHandler.Execute(line)
 
 			if result.Error != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
+				r.printError(result.Error)
 			} else if result.Output != "" {
 				fmt.Println(result.Output)
+				if cmd != "output" {
+					r.lastOutput = theme.Strip(result.Output)
+					if result.Raw != "" {
+						r.lastOutput = result.Raw
+					}
+				}
 			}
 
 			if result.ShouldQuit {
@@ -143,6 +198,7 @@ func (r *REPL) Run() error {
 		if accumulator.IsComplete() {
 			query := accumulator.Get()
 			accumulator.Clear()
+			r.lastStatement = query
 
 			// 改行を空白に置換して1行形式で履歴に保存
 			historyEntry := strings.Join(strings.Fields(query), " ")
@@ -167,7 +223,7 @@ func (r *REPL) executeQuery(query string) {
 		// Prompt for values
 		values, cancelled, promptErr := placeholder.PromptForValues(r.readline, count, query)
 		if promptErr != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", promptErr)
+			r.printError(promptErr)
 			return
 		}
 		if cancelled {
@@ -180,14 +236,77 @@ func (r *REPL) executeQuery(query string) {
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		r.printError(err)
 		return
 	}
 
+	r.applySchemaChange(query)
+
 	output := r.renderer.Render(result)
+	r.lastOutput = theme.Strip(output)
 	fmt.Println(output)
 }
 
+// printError writes an error message in the color of the theme.
+func (r *REPL) printError(err error) {
+	fmt.Fprintln(os.Stderr, theme.Paint(r.theme.Error, fmt.Sprintf("Error: %v", err)))
+}
+
+// applySchemaChange keeps the metadata cache in step with DDL and USE
+// statements executed in the REPL.
+func (r *REPL) applySchemaChange(query string) {
+	change := completion.DetectSchemaChange(query)
+	if change.IsEmpty() {
+		return
+	}
+
+	if change.Use != "" {
+		r.cache.SetCurrentDatabase(change.Use)
+		r.cache.InvalidateObjects("")
+	}
+	for _, table := range change.Tables {
+		r.cache.InvalidateTable(table.Schema, table.Name)
+	}
+	if change.TableList {
+		r.cache.InvalidateTableList("")
+	}
+	// Dropping a table drops its triggers
+	if change.Objects || change.TableList {
+		r.cache.InvalidateObjects("")
+		for _, table := range change.Tables {
+			if table.Schema != "" {
+				r.cache.InvalidateObjects(table.Schema)
+			}
+		}
+	}
+	if change.Databases {
+		r.cache.InvalidateDatabases()
+	}
+}
+
+// edit puts a statement back for editing as if it had been typed: all
+// lines but the last are entered, the last one is left on the prompt.
+func (r *REPL) edit(statement string) {
+	r.accumulator.Clear()
+	lines := strings.Split(statement, "\n")
+	prompt := r.getPrompt()
+	for _, line := range lines[:len(lines)-1] {
+		fmt.Println(prompt + line)
+		r.accumulator.Add(line)
+		prompt = continuationPrompt
+	}
+	r.prefill = lines[len(lines)-1]
+}
+
+// setSession switches the REPL to another database.
+func (r *REPL) setSession(s *Session) {
+	r.connector = s.Connector
+	r.cache = s.Cache
+	r.dialect = s.Dialect
+	r.completer.SetSource(s.Cache, s.Dialect)
+	r.highlighter.SetDialect(s.Dialect)
+}
+
 // Close cleans up REPL resources.
 func (r *REPL) Close() error {
 	if r.readline != nil {
@@ -205,10 +324,30 @@ func (r *REPL) SetFormat(format render.OutputFormat) {
 	case render.FormatJSON:
 		r.renderer = render.NewJSONRenderer()
 	default:
-		r.renderer = render.NewTableRenderer()
+		r.renderer = r.newTableRenderer()
+	}
+}
+
+func (r *REPL) newTableRenderer() *render.TableRenderer {
+	tr := render.NewTableRenderer()
+	tr.Theme = r.theme
+	return tr
+}
+
+// SetTheme changes the colors of highlighting and result tables.
+func (r *REPL) SetTheme(t theme.Theme) {
+	r.theme = t
+	r.highlighter.SetTheme(t)
+	if tr, ok := r.renderer.(*render.TableRenderer); ok {
+		tr.Theme = t
 	}
 }
 
+// Theme returns the current color theme.
+func (r *REPL) Theme() theme.Theme {
+	return r.theme
+}
+
 // GetFormat returns the current output format.
 func (r *REPL) GetFormat() render.OutputFormat {
 	return r.outputFormat
@@ -224,6 +363,26 @@ func (r *REPL) Connector() db.Connector {
 	return r.connector
 }
 
+// Dialect returns the database dialect.
+func (r *REPL) Dialect() db.Dialect {
+	return r.dialect
+}
+
+// Completer returns the SQL completer.
+func (r *REPL) Completer() *completion.SQLCompleter {
+	return r.completer
+}
+
+// Highlighter returns the SQL syntax highlighter.
+func (r *REPL) Highlighter() *highlight.SQLHighlighter {
+	return r.highlighter
+}
+
+// LastOutput returns the output of the last query or command.
+func (r *REPL) LastOutput() string {
+	return r.lastOutput
+}
+
 // Renderer returns the current renderer.
 func (r *REPL) Renderer() render.Renderer {
 	return r.renderer
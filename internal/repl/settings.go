@@ -0,0 +1,68 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mastar3104/sqcl/internal/completion"
+	"github.com/mastar3104/sqcl/internal/theme"
+)
+
+// setCommand shows all settings or changes one of them.
+func (h *CommandHandler) setCommand(args []string) CommandResult {
+	if len(args) == 0 {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("completion = %s\n", h.repl.Completer().MatchMode()))
+		sb.WriteString(fmt.Sprintf("keyword-case = %s\n", h.repl.Completer().KeywordCase()))
+		sb.WriteString(fmt.Sprintf("theme = %s\n", h.repl.Theme().Name))
+		return CommandResult{Output: strings.TrimSuffix(sb.String(), "\n")}
+	}
+	if len(args) != 2 {
+		return CommandResult{Error: fmt.Errorf("usage: :set <option> <value>")}
+	}
+
+	option, value := strings.ToLower(args[0]), args[1]
+	switch option {
+	case "completion":
+		mode, err := completion.ParseMatchMode(value)
+		if err != nil {
+			return CommandResult{Error: err}
+		}
+		h.repl.Completer().SetMatchMode(mode)
+		return CommandResult{Output: fmt.Sprintf("Completion set to: %s", mode)}
+	case "keyword-case":
+		keywordCase, err := completion.ParseKeywordCase(value)
+		if err != nil {
+			return CommandResult{Error: err}
+		}
+		h.repl.Completer().SetKeywordCase(keywordCase)
+		return CommandResult{Output: fmt.Sprintf("Keyword case set to: %s", keywordCase)}
+	case "theme":
+		t, err := theme.Get(strings.ToLower(value))
+		if err != nil {
+			return CommandResult{Error: err}
+		}
+		h.repl.SetTheme(t)
+		return CommandResult{Output: fmt.Sprintf("Theme set to: %s", t.Name)}
+	default:
+		return CommandResult{Error: fmt.Errorf("unknown option: %s (available: completion, keyword-case, theme)", option)}
+	}
+}
+
+// settingArgs completes option names and their values.
+func (h *CommandHandler) settingArgs(args []string) []string {
+	switch len(args) {
+	case 0:
+		return []string{"completion", "keyword-case", "theme"}
+	case 1:
+		switch strings.ToLower(args[0]) {
+		case "completion":
+			return []string{"fuzzy", "prefix"}
+		case "keyword-case":
+			return []string{"lower", "preserve", "upper"}
+		case "theme":
+			return theme.Names()
+		}
+	}
+	return nil
+}
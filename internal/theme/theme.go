@@ -0,0 +1,136 @@
+// Package theme defines the colors of highlighted SQL and result tables.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const reset = "\033[0m"
+
+// Theme holds the ANSI escape sequence of each kind of colored text. An
+// empty sequence leaves the text as it is.
+type Theme struct {
+	Name string
+
+	// SQL highlighting
+	Keyword     string
+	String      string
+	Number      string
+	Comment     string
+	Placeholder string
+	Hint        string
+	// Unknown marks names missing from the schema and Match the
+	// parenthesis matching the one at the cursor.
+	Unknown string
+	Match   string
+
+	// Result tables and messages
+	Header  string
+	Null    string
+	Numeric string
+	Error   string
+}
+
+var themes = map[string]Theme{
+	"dark": {
+		Name:        "dark",
+		Keyword:     "\033[36m",
+		String:      "\033[33m",
+		Number:      "\033[32m",
+		Comment:     "\033[90m",
+		Placeholder: "\033[35m",
+		Hint:        "\033[2m",
+		Unknown:     "\033[4;31m",
+		Match:       "\033[1;7m",
+		Header:      "\033[1;36m",
+		Null:        "\033[2m",
+		Numeric:     "\033[32m",
+		Error:       "\033[31m",
+	},
+	"light": {
+		Name:        "light",
+		Keyword:     "\033[34m",
+		String:      "\033[35m",
+		Number:      "\033[32m",
+		Comment:     "\033[2;37m",
+		Placeholder: "\033[4;35m",
+		Hint:        "\033[2m",
+		Unknown:     "\033[4;31m",
+		Match:       "\033[1;7m",
+		Header:      "\033[1;34m",
+		Null:        "\033[2m",
+		Numeric:     "\033[34m",
+		Error:       "\033[31m",
+	},
+	"monochrome": {
+		Name:        "monochrome",
+		Keyword:     "\033[1m",
+		Comment:     "\033[2m",
+		Placeholder: "\033[4m",
+		Hint:        "\033[2m",
+		Unknown:     "\033[4m",
+		Match:       "\033[7m",
+		Header:      "\033[1m",
+		Null:        "\033[2m",
+		Error:       "\033[1m",
+	},
+	"none": {Name: "none"},
+}
+
+// Default is the theme used unless another is configured.
+const Default = "dark"
+
+// Get returns the theme with the given name.
+func Get(name string) (Theme, error) {
+	t, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return t, nil
+}
+
+// Names returns the names of all themes.
+func Names() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the theme to start with. A configured theme is used
+// as is; otherwise colors are off when NO_COLOR is set. Colors are always
+// off when stdout is not a terminal.
+func Select(configured string, terminal bool) (Theme, error) {
+	if !terminal {
+		return themes["none"], nil
+	}
+	if configured != "" {
+		return Get(configured)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return themes["none"], nil
+	}
+	return themes[Default], nil
+}
+
+// Paint wraps text in color. Text is returned unchanged when color is
+// empty.
+func Paint(color, text string) string {
+	if color == "" || text == "" {
+		return text
+	}
+	return color + text + reset
+}
+
+var escapes = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// Strip removes color escape sequences from s.
+func Strip(s string) string {
+	return escapes.ReplaceAllString(s, "")
+}
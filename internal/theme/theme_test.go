@@ -0,0 +1,44 @@
+package theme
+
+import "testing"
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		terminal   bool
+		noColor    string
+		want       string
+	}{
+		{name: "default", terminal: true, want: Default},
+		{name: "configured", configured: "light", terminal: true, want: "light"},
+		{name: "NO_COLOR", terminal: true, noColor: "1", want: "none"},
+		{name: "configured overrides NO_COLOR", configured: "monochrome", terminal: true, noColor: "1", want: "monochrome"},
+		{name: "not a terminal", configured: "dark", want: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			got, err := Select(tt.configured, tt.terminal)
+			if err != nil || got.Name != tt.want {
+				t.Errorf("Select(%q, %v) = %q, %v, want %q", tt.configured, tt.terminal, got.Name, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Select("neon", true); err == nil {
+		t.Error("Select(neon) succeeded")
+	}
+}
+
+func TestPaintAndStrip(t *testing.T) {
+	dark, _ := Get("dark")
+	painted := Paint(dark.Header, "id") + " " + Paint(dark.Null, "NULL")
+	if got := Strip(painted); got != "id NULL" {
+		t.Errorf("Strip(%q) = %q", painted, got)
+	}
+	if got := Paint("", "id"); got != "id" {
+		t.Errorf("Paint without color = %q", got)
+	}
+}